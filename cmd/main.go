@@ -9,6 +9,7 @@ import (
 	"github.com/synera-br/golang-cloud-collector/configs"
 
 	_ "github.com/synera-br/golang-cloud-collector/docs/swagger"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
 	"github.com/synera-br/golang-cloud-collector/internal/core/repository"
 	"github.com/synera-br/golang-cloud-collector/internal/core/service"
 	handler "github.com/synera-br/golang-cloud-collector/internal/infra/handler/rest"
@@ -34,6 +35,23 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	if err := cfg.Provider.Validate(); err != nil {
+		log.Fatalln(err)
+	}
+
+	if cfg.Backstage == nil {
+		cfg.Backstage = &entity.BackstageConfig{}
+	}
+	if err := cfg.Backstage.Validate(); err != nil {
+		log.Fatalln(err)
+	}
+	// grupos de gerenciamento viram Domain e não possuem tags: sem o owner padrão todos seriam descartados
+	for _, account := range cfg.Provider.AzureAccounts() {
+		if account.ManagementGroups && cfg.Backstage.Mapping.Owner.Default == "" {
+			log.Fatalf("the azure account %s collects management groups and requires backstage.mapping.owner.default", account.GetName())
+		}
+	}
+
 	// STARTS OTEL
 	ctx := context.Background()
 	otl, err := otelpkg.NewOtel(ctx, cfg.FileConfig.ConfigPath, cfg.FileConfig.FileName, cfg.FileConfig.Extentsion)
@@ -72,25 +90,70 @@ func main() {
 		log.Fatalln(err)
 	}
 
+	providers := service.NewProviderRegistry()
+
 	// Azure resources
-	azureRepository, err := repository.NewAzureRepository(&cfg.Provider.Azure, otl)
-	if err != nil {
-		log.Fatalln(err)
+	var azureServices []service.AzureServiceInterface
+	for _, account := range cfg.Provider.AzureAccounts() {
+		azureRepository, err := repository.NewAzureRepository(&account, otl)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		azureService, err := service.NewAzureService(&account, &cfg.Backstage.Mapping, &azureRepository, &cc, otl)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		azureServices = append(azureServices, azureService)
+		providers.Register(azureService)
 	}
 
-	azureService, err := service.NewAzureService(&azureRepository, &cc, otl)
-	if err != nil {
-		log.Fatalln(err)
+	if len(azureServices) > 0 {
+		handler.NewAzureHandlerHttp(azureServices, otl, rest.RouterGroup, rest.ValidateToken)
 	}
 
-	handler.NewAzureHandlerHttp(azureService, otl, rest.RouterGroup, rest.ValidateToken)
-	if err != nil {
-		log.Fatalln("error is: ", err.Error())
+	// AWS resources
+	if !cfg.Provider.AWS.IsEmpty() {
+		awsRepository, err := repository.NewAWSRepository(&cfg.Provider.AWS, otl)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		awsService, err := service.NewAWSService(&awsRepository, &cc, otl)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		handler.NewAWSHandlerHttp(awsService, otl, rest.RouterGroup, rest.ValidateToken)
+		providers.Register(awsService)
+	}
+
+	// GCP resources
+	if !cfg.Provider.GCP.IsEmpty() {
+		gcpRepository, err := repository.NewGCPRepository(&cfg.Provider.GCP, otl)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		gcpService, err := service.NewGCPService(&gcpRepository, &cc, otl)
+		if err != nil {
+			log.Fatalln(err)
+		}
+
+		providers.Register(gcpService)
 	}
 
 	// Backstage
-	backstageService := service.NewBackstageService(azureService, amqp, cc, otl)
+	backstageService, err := service.NewBackstageService(providers, cfg.Backstage, amqp, cc, otl)
+	if err != nil {
+		log.Fatalln(err)
+	}
 	handler.NewBackstageHandlerHttp(backstageService, otl, rest.RouterGroup, rest.ValidateToken, nrgin.Middleware(app))
+
+	if len(azureServices) > 0 {
+		handler.NewEventGridHandlerHttp(azureServices, backstageService, otl, rest.RouterGroup)
+	}
 	if err != nil {
 		log.Fatalln("error is: ", err.Error())
 	}
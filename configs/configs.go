@@ -7,6 +7,7 @@ import (
 
 	"github.com/spf13/viper"
 	_ "github.com/spf13/viper"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
 )
 
 var AppConfig ConfigPath
@@ -62,7 +63,8 @@ type Connections struct {
 	PathConfigFile string `mapstructure:"path_config_file"`
 	Paths          *ConfigPath
 	FileConfig     *FileConfig
-	Provider       *Provider `json:"cloud_provider" mapstructure:"cloud_provider"`
+	Provider       *Provider               `json:"cloud_provider" mapstructure:"cloud_provider"`
+	Backstage      *entity.BackstageConfig `json:"backstage" mapstructure:"backstage"`
 }
 
 func LoadConfig() (*Connections, error) {
@@ -109,5 +111,6 @@ func LoadConfig() (*Connections, error) {
 		Paths:          &AppConfig,
 		FileConfig:     &fc,
 		Provider:       cfg.Provider,
+		Backstage:      cfg.Backstage,
 	}, err
 }
@@ -2,26 +2,75 @@ package configs
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
 )
 
 type Provider struct {
-	Azure entity.AzureProvider `json:"azure" mapstructure:"azure"`
+	Azure []entity.AzureProvider `json:"azure" mapstructure:"azure"`
+	AWS   entity.AWSProvider     `json:"aws" mapstructure:"aws"`
+	GCP   entity.GCPProvider     `json:"gcp" mapstructure:"gcp"`
 }
 
 func (p *Provider) Validate() error {
 
 	var hasProvider error = nil
-	if !p.Azure.IsEmpty() {
-		if err := p.Azure.Validate(); err != nil {
+	if err := p.validateAzure(); err != nil {
+		hasProvider = err
+	}
+
+	if !p.AWS.IsEmpty() {
+		if err := p.AWS.Validate(); err != nil {
+			hasProvider = err
+		}
+	}
+
+	if !p.GCP.IsEmpty() {
+		if err := p.GCP.Validate(); err != nil {
 			hasProvider = err
 		}
 	}
 
-	if p.Azure.IsEmpty() {
+	if len(p.AzureAccounts()) == 0 && p.AWS.IsEmpty() && p.GCP.IsEmpty() {
 		hasProvider = errors.New("no provider has benn configured")
 	}
 
 	return hasProvider
 }
+
+// AzureAccounts
+// Retorna as contas Azure configuradas, ignorando as entradas vazias
+func (p *Provider) AzureAccounts() []entity.AzureProvider {
+	var accounts []entity.AzureProvider
+	for _, account := range p.Azure {
+		if !account.IsEmpty() {
+			accounts = append(accounts, account)
+		}
+	}
+	return accounts
+}
+
+// validateAzure
+// Com mais de uma conta, cada conta precisa de um nome único
+func (p *Provider) validateAzure() error {
+
+	accounts := p.AzureAccounts()
+	names := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		if err := account.Validate(); err != nil {
+			return err
+		}
+
+		if len(accounts) > 1 && account.Name == "" {
+			return errors.New("azure account name is required when more than one account is configured")
+		}
+
+		if names[account.GetName()] {
+			return fmt.Errorf("azure account %s is duplicated", account.GetName())
+		}
+		names[account.GetName()] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package entity
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrAccountNotFound a conta consultada não é a conta das credenciais
+var ErrAccountNotFound = errors.New("account not found")
+
+// ErrRegionNotFound a região consultada não está entre as regiões configuradas
+var ErrRegionNotFound = errors.New("region not configured")
+
+type AWSProviderInterface interface {
+	GetAccount(ctx context.Context, id string) (*AWSAccount, error)
+	ListResourcesByTag(ctx context.Context, tagKey, tagValue string) ([]*AWSResource, error)
+	ListResourcesByRegion(ctx context.Context, region string) ([]*AWSResource, error)
+	ListResources(ctx context.Context) ([]*AWSResource, error)
+}
+
+// AWSProvider
+// Configuração de acesso a conta AWS
+// Regions lista de regiões coletadas. Quando vazia usa somente Region
+// Endpoint permite apontar os clientes para um serviço local (ex.: localstack)
+type AWSProvider struct {
+	AccountID       string   `json:"account_id" binding:"required" mapstructure:"account_id"`
+	AccessKeyID     string   `json:"access_key_id" binding:"required" mapstructure:"access_key_id"`
+	SecretAccessKey string   `json:"secret_access_key" binding:"required" mapstructure:"secret_access_key"`
+	SessionToken    string   `json:"session_token,omitempty" mapstructure:"session_token"`
+	Region          string   `json:"region" binding:"required" mapstructure:"region"`
+	Regions         []string `json:"regions,omitempty" mapstructure:"regions"`
+	Endpoint        string   `json:"endpoint,omitempty" mapstructure:"endpoint"`
+}
+
+type AWSAccount struct {
+	ID     string `json:"id" binding:"required"`
+	ARN    string `json:"arn" binding:"required"`
+	UserID string `json:"user_id" binding:"required"`
+}
+
+// AWSResource
+// Recurso da AWS normalizado a partir do ARN
+// Service serviço dono do recurso. Exemplo ec2
+// Type tipo do recurso dentro do serviço. Exemplo instance
+type AWSResource struct {
+	ARN       string            `json:"arn" binding:"required"`
+	Name      string            `json:"name" binding:"required"`
+	Service   string            `json:"service" binding:"required"`
+	Type      string            `json:"type,omitempty"`
+	Region    string            `json:"region,omitempty"`
+	AccountID string            `json:"account_id" binding:"required"`
+	Tags      map[string]string `json:"tags,omitempty"`
+}
+
+func (a *AWSProvider) Validate() error {
+
+	var err error = nil
+	if a.AccountID == "" {
+		err = errors.New("the AWS account ID cannot be empty")
+	}
+
+	if a.AccessKeyID == "" {
+		err = errors.New("the AWS access key ID cannot be empty")
+	}
+
+	if a.SecretAccessKey == "" {
+		err = errors.New("the AWS secret access key cannot be empty")
+	}
+
+	if a.Region == "" {
+		err = errors.New("the AWS region cannot be empty")
+	}
+
+	return err
+}
+
+func (a *AWSProvider) IsEmpty() bool {
+	return a.AccountID == "" &&
+		a.AccessKeyID == "" &&
+		a.SecretAccessKey == "" &&
+		a.Region == ""
+}
+
+// GetRegions
+// Retorna as regiões configuradas para coleta
+func (a *AWSProvider) GetRegions() []string {
+	if len(a.Regions) > 0 {
+		return a.Regions
+	}
+	return []string{a.Region}
+}
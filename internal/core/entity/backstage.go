@@ -2,16 +2,25 @@ package entity
 
 import (
 	"context"
-	"errors"
-
-	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"encoding/json"
+	"fmt"
+	"strings"
 )
 
 type BackstageInterface interface {
-	TriggerSyncProvider(ctx context.Context, trigger *Trigger) ([]KindReource, error)
-	GetAllKinds(ctx context.Context, filter FilterKind) ([]KindReource, error)
+	TriggerSyncProvider(ctx context.Context, trigger *Trigger) ([]BackstageEntity, error)
+	GetAllKinds(ctx context.Context, filter FilterKind) ([]BackstageEntity, error)
+	GetAllDocuments(ctx context.Context, filter FilterKind, profile string) ([]BackstageDocument, error)
+	RenderProfile(ctx context.Context, profile string, entities []BackstageEntity) ([]BackstageDocument, error)
+	PublishCloudResources(ctx context.Context, provider CloudProviderInterface, resources []CloudResource) ([]BackstageEntity, error)
 }
 
+// BackstageAPIVersion versão do catálogo das entidades emitidas
+const BackstageAPIVersion = "backstage.io/v1alpha1"
+
+// BackstageDefaultNamespace namespace das entidades e referências sem namespace
+const BackstageDefaultNamespace = "default"
+
 type CloudProvider int
 
 // Defina constantes para o enum
@@ -75,10 +84,16 @@ type FilterTag struct {
 	Value string `json:"value,omitempty"`
 }
 
+// Trigger
+// Provider vazio sincroniza todos os provedores configurados
+// Account vazio sincroniza todas as contas do provedor
+// Full ignora o modo incremental dos provedores e lista todos os recursos. Concluída a listagem, os cursores são reiniciados
 type Trigger struct {
-	Provider       string         `json:"provider" binding:"required"`
+	Provider       string         `json:"provider,omitempty"`
+	Account        string         `json:"account,omitempty"`
 	TargetResource FilterResource `json:"target_resource,omitempty"`
 	TargetTags     FilterTag      `json:"target_tag,omitempty"`
+	Full           bool           `json:"full,omitempty"`
 }
 
 type Depends struct {
@@ -86,53 +101,219 @@ type Depends struct {
 	Value string `json:"value" binding:"required"`
 }
 
-type Metadata struct {
-	metav1.ObjectMeta
-	Description string   `json:"description,omitempty"`
-	Tags        []string `json:"tags,omitempty"`
+// BackstageEntity
+// Entidade do catálogo do Backstage publicada pelo coletor
+// Source recurso que originou a entidade, usado pelos perfis de renderização. Não é publicado
+type BackstageEntity struct {
+	APIVersion string            `json:"apiVersion" binding:"required"`
+	Kind       string            `json:"kind" binding:"required"`
+	Metadata   BackstageMetadata `json:"metadata" binding:"required"`
+	Spec       Resource          `json:"spec" binding:"required"`
+	Source     *BackstageSource  `json:"-"`
+}
+
+// BackstageSource
+// Recurso normalizado e seus pais, do mais próximo até a raiz
+type BackstageSource struct {
+	Resource CloudResource
+	Parents  []CloudResource
+}
+
+// BackstageTemplateData
+// Dados recebidos pelos templates dos perfis de renderização
+// Resource recurso normalizado, Parents pais do mais próximo até a raiz e Tags tags do recurso
+// Entity entidade padrão, com nome, namespace e referências já resolvidos
+type BackstageTemplateData struct {
+	Resource CloudResource
+	Parents  []CloudResource
+	Tags     map[string]string
+	Entity   BackstageEntity
+}
+
+// BackstageDocument
+// Entidade gerada por um perfil de renderização
+type BackstageDocument map[string]interface{}
+
+// BackstageMetadata
+// Apenas os campos de metadata aceitos pelo catálogo do Backstage
+type BackstageMetadata struct {
+	Name        string            `json:"name" binding:"required"`
+	Namespace   string            `json:"namespace,omitempty"`
+	Title       string            `json:"title,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Links       []BackstageLink   `json:"links,omitempty"`
 }
 
-type KindReource struct {
-	Metadata Metadata `json:"metadata" binding:"required"`
-	Spec     Resource `json:"spec" binding:"required"`
-	Kind     string   `json:"kind" binding:"required"`
+// BackstageLink
+// Link exibido na página da entidade
+type BackstageLink struct {
+	URL   string `json:"url" binding:"required"`
+	Title string `json:"title,omitempty"`
+	Icon  string `json:"icon,omitempty"`
+	Type  string `json:"type,omitempty"`
 }
 
+// Resource
+// SubdomainOf usado apenas por entidades Domain. Referência ao domínio pai
+// Profile, Children e MemberOf usados pelas entidades Group e User
+// Os campos publicados dependem do kind, ver BackstageEntity.MarshalJSON
 type Resource struct {
 	Type         string   `json:"type" binding:"required"`
 	Owner        string   `json:"owner" binding:"required"`
 	System       string   `json:"system,omitempty"`
+	Lifecycle    string   `json:"lifecycle,omitempty"`
+	SubdomainOf  string   `json:"subdomainOf,omitempty"`
+	Profile      *Profile `json:"profile,omitempty"`
+	Children     []string `json:"children,omitempty"`
+	MemberOf     []string `json:"memberOf,omitempty"`
 	DependsOn    []string `json:"dependsOn,omitempty"`
 	DependencyOf []string `json:"dependencyOf,omitempty"`
 }
 
+type Profile struct {
+	DisplayName string `json:"displayName,omitempty"`
+	Email       string `json:"email,omitempty"`
+}
+
 type FilterKind struct {
 	Name      string `json:"name"`
 	Kind      string `json:"kind" `
 	Namespace string `json:"namespace" `
 }
 
-func (k *KindReource) Validate() error {
+// NewBackstageEntity
+// Entidade com apiVersion, namespace padrão e mapas de metadata inicializados
+func NewBackstageEntity(kind, name string) BackstageEntity {
+	return BackstageEntity{
+		APIVersion: BackstageAPIVersion,
+		Kind:       kind,
+		Metadata: BackstageMetadata{
+			Name:        name,
+			Namespace:   BackstageDefaultNamespace,
+			Labels:      make(map[string]string),
+			Annotations: make(map[string]string),
+		},
+	}
+}
 
-	if k.Kind == "" {
-		k.Kind = "Resource"
+// Ref
+// Referência completa da entidade. Exemplo resource:default/storage01
+func (e *BackstageEntity) Ref() string {
+	return EntityRef(e.Kind, e.Metadata.Namespace, e.Metadata.Name)
+}
+
+// EntityRef
+// Monta a referência kind:namespace/name. Namespace vazio usa o namespace padrão
+func EntityRef(kind, namespace, name string) string {
+	if namespace == "" {
+		namespace = BackstageDefaultNamespace
 	}
+	return fmt.Sprintf("%s:%s/%s", strings.ToLower(kind), namespace, name)
+}
 
-	if k.Metadata.Namespace == "" {
-		k.Metadata.Namespace = "default"
+// QualifyRef
+// Completa uma referência abreviada ([kind:][namespace/]name) com o kind e o namespace padrão
+func QualifyRef(ref, defaultKind, defaultNamespace string) string {
+	if ref == "" {
+		return ""
 	}
 
-	if k.Metadata.Name == "" {
-		return errors.New("the resource name cannot be empty")
+	kind := defaultKind
+	if i := strings.Index(ref, ":"); i >= 0 {
+		kind, ref = ref[:i], ref[i+1:]
 	}
 
-	if k.Spec.Type == "" {
-		return errors.New("the resource type cannot be empty")
+	namespace := defaultNamespace
+	if i := strings.Index(ref, "/"); i >= 0 {
+		namespace, ref = ref[:i], ref[i+1:]
+	}
+
+	return EntityRef(kind, namespace, ref)
+}
+
+// SanitizeRef
+// Completa a referência como QualifyRef e adapta o namespace e o nome às regras do Backstage.
+// Retorna vazio quando não sobra nenhum caractere válido no nome
+func SanitizeRef(ref, defaultKind, defaultNamespace string) string {
+	if ref == "" {
+		return ""
+	}
+
+	kind := defaultKind
+	if i := strings.Index(ref, ":"); i >= 0 {
+		kind, ref = ref[:i], ref[i+1:]
+	}
+
+	namespace := defaultNamespace
+	if i := strings.Index(ref, "/"); i >= 0 {
+		namespace, ref = SanitizeNamespace(ref[:i]), ref[i+1:]
+	}
+
+	name := SanitizeName(ref)
+	if name == "" {
+		return ""
+	}
+	return EntityRef(kind, namespace, name)
+}
+
+// MarshalJSON
+// Publica no spec apenas os campos do schema do kind. Os campos obrigatórios
+// children (Group) e memberOf (User) são emitidos mesmo vazios
+func (e BackstageEntity) MarshalJSON() ([]byte, error) {
+
+	type entity BackstageEntity
+	return json.Marshal(struct {
+		entity
+		Spec map[string]interface{} `json:"spec"`
+	}{
+		entity: entity(e),
+		Spec:   e.Spec.fields(e.Kind),
+	})
+}
+
+func (r Resource) fields(kind string) map[string]interface{} {
+
+	result := make(map[string]interface{})
+	set := func(key string, value string) {
+		if value != "" {
+			result[key] = value
+		}
+	}
+	list := func(key string, values []string, required bool) {
+		if len(values) > 0 {
+			result[key] = values
+		} else if required {
+			result[key] = []string{}
+		}
 	}
 
-	if k.Spec.Owner == "" {
-		return errors.New("the resource owner cannot be empty")
+	switch kind {
+	case "Group":
+		set("type", r.Type)
+		if r.Profile != nil {
+			result["profile"] = r.Profile
+		}
+		list("children", r.Children, true)
+	case "User":
+		if r.Profile != nil {
+			result["profile"] = r.Profile
+		}
+		list("memberOf", r.MemberOf, true)
+	case "Domain":
+		set("type", r.Type)
+		set("owner", r.Owner)
+		set("subdomainOf", r.SubdomainOf)
+	default:
+		set("type", r.Type)
+		set("owner", r.Owner)
+		set("system", r.System)
+		set("lifecycle", r.Lifecycle)
+		list("dependsOn", r.DependsOn, false)
+		list("dependencyOf", r.DependencyOf, false)
 	}
 
-	return nil
+	return result
 }
@@ -0,0 +1,197 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// BackstageConfig
+// Configuração da geração das entidades do Backstage
+// Naming estratégia de nomes das entidades dos recursos de nuvem
+// Mapping origem dos campos da entidade a partir das tags
+// Profiles perfis de renderização, escolhidos por requisição (?profile=) ou por sink
+// Sinks destinos AMQP das entidades. Sem configuração, publica o modelo padrão em collector/backstage/manifests
+type BackstageConfig struct {
+	Naming   BackstageNaming    `json:"naming,omitempty" mapstructure:"naming"`
+	Mapping  BackstageMapping   `json:"mapping,omitempty" mapstructure:"mapping"`
+	Profiles []BackstageProfile `json:"profiles,omitempty" mapstructure:"profiles"`
+	Sinks    []BackstageSink    `json:"sinks,omitempty" mapstructure:"sinks"`
+}
+
+func (c *BackstageConfig) Validate() error {
+	return errors.Join(c.Naming.Validate(), c.Mapping.Validate(), c.validateProfiles())
+}
+
+// ErrProfileNotFound perfil de renderização não configurado
+var ErrProfileNotFound = errors.New("profile not configured")
+
+// Formatos gerados pelos templates dos perfis
+const (
+	BackstageProfileYAML = "yaml"
+	BackstageProfileJSON = "json"
+)
+
+// BackstageProfile
+// Perfil de renderização definido por um template text/template
+// File caminho do template. Format formato gerado pelo template: yaml (padrão) ou json
+type BackstageProfile struct {
+	Name   string `json:"name" mapstructure:"name"`
+	File   string `json:"file" mapstructure:"file"`
+	Format string `json:"format,omitempty" mapstructure:"format"`
+}
+
+// GetFormat
+// Retorna o formato configurado ou yaml quando não configurado
+func (p *BackstageProfile) GetFormat() string {
+	if p.Format == "" {
+		return BackstageProfileYAML
+	}
+	return strings.ToLower(p.Format)
+}
+
+// BackstageSink
+// Destino AMQP das entidades publicadas. Profile vazio publica o modelo padrão
+type BackstageSink struct {
+	Name     string `json:"name" mapstructure:"name"`
+	Exchange string `json:"exchange" mapstructure:"exchange"`
+	RouteKey string `json:"route_key" mapstructure:"route_key"`
+	Queue    string `json:"queue" mapstructure:"queue"`
+	Profile  string `json:"profile,omitempty" mapstructure:"profile"`
+}
+
+// GetSinks
+// Retorna os sinks configurados ou o destino padrão do coletor
+func (c *BackstageConfig) GetSinks() []BackstageSink {
+	if len(c.Sinks) == 0 {
+		return []BackstageSink{{Name: "manifests", Exchange: "collector", RouteKey: "backstage", Queue: "manifests"}}
+	}
+	return c.Sinks
+}
+
+// validateProfiles
+// Perfis com nome único, arquivo e formato suportado. Os sinks só podem usar perfis configurados
+func (c *BackstageConfig) validateProfiles() error {
+
+	names := make(map[string]bool, len(c.Profiles))
+	for _, profile := range c.Profiles {
+		if profile.Name == "" || profile.File == "" {
+			return errors.New("the backstage profile requires name and file")
+		}
+		if names[profile.Name] {
+			return fmt.Errorf("the backstage profile %s is duplicated", profile.Name)
+		}
+		names[profile.Name] = true
+
+		if format := profile.GetFormat(); format != BackstageProfileYAML && format != BackstageProfileJSON {
+			return fmt.Errorf("the backstage profile format %s is not supported", profile.Format)
+		}
+	}
+
+	for _, sink := range c.Sinks {
+		if sink.Exchange == "" || sink.RouteKey == "" || sink.Queue == "" {
+			return fmt.Errorf("the backstage sink %s requires exchange, route_key and queue", sink.Name)
+		}
+		if sink.Profile != "" && !names[sink.Profile] {
+			return fmt.Errorf("the backstage sink %s uses the unknown profile %s", sink.Name, sink.Profile)
+		}
+	}
+
+	return nil
+}
+
+// BackstageMapping
+// Regras que preenchem spec.owner, spec.system, spec.lifecycle, o namespace, a descrição e o título.
+// Sem configuração, owner e system são lidos das tags owner e system
+type BackstageMapping struct {
+	Owner       BackstageMappingRule `json:"owner,omitempty" mapstructure:"owner"`
+	System      BackstageMappingRule `json:"system,omitempty" mapstructure:"system"`
+	Lifecycle   BackstageMappingRule `json:"lifecycle,omitempty" mapstructure:"lifecycle"`
+	Namespace   BackstageMappingRule `json:"namespace,omitempty" mapstructure:"namespace"`
+	Description BackstageMappingRule `json:"description,omitempty" mapstructure:"description"`
+	Title       BackstageMappingRule `json:"title,omitempty" mapstructure:"title"`
+}
+
+// BackstageMappingRule
+// Tags chaves em ordem de prioridade, comparadas sem diferenciar maiúsculas. A primeira com valor vence
+// Inherit procura as tags nos níveis acima quando o recurso não as possui: recurso pai, grupo de recursos e, em seguida, a assinatura
+// Default valor usado quando nenhuma tag foi encontrada
+type BackstageMappingRule struct {
+	Tags    []string `json:"tags,omitempty" mapstructure:"tags"`
+	Inherit bool     `json:"inherit,omitempty" mapstructure:"inherit"`
+	Default string   `json:"default,omitempty" mapstructure:"default"`
+}
+
+// GetOwner
+// Regra do owner ou a tag owner quando nenhuma tag foi configurada
+func (m *BackstageMapping) GetOwner() BackstageMappingRule {
+	return m.Owner.withTags("owner")
+}
+
+// GetSystem
+// Regra do system ou a tag system quando nenhuma tag foi configurada
+func (m *BackstageMapping) GetSystem() BackstageMappingRule {
+	return m.System.withTags("system")
+}
+
+func (r BackstageMappingRule) withTags(tags ...string) BackstageMappingRule {
+	if len(r.Tags) == 0 {
+		r.Tags = tags
+	}
+	return r
+}
+
+func (m *BackstageMapping) Validate() error {
+
+	if m.Namespace.Default != "" && !ValidNamespace(m.Namespace.Default) {
+		return fmt.Errorf("the mapping namespace default %s is not a valid namespace", m.Namespace.Default)
+	}
+	for _, rule := range []BackstageMappingRule{m.Owner, m.System, m.Lifecycle, m.Namespace, m.Description, m.Title} {
+		for _, tag := range rule.Tags {
+			if strings.TrimSpace(tag) == "" {
+				return errors.New("the mapping tags cannot be empty")
+			}
+		}
+	}
+
+	return nil
+}
+
+// Resolve
+// Valor da primeira tag da regra no recurso ou, com Inherit, nos pais na ordem recebida.
+// source indica a origem: tag:<chave> no próprio recurso e tag:<nível>:<chave> quando herdado.
+// O Default não é aplicado, para que o chamador decida a precedência de outras origens
+func (r *BackstageMappingRule) Resolve(resource *CloudResource, parents []*CloudResource) (value string, source string) {
+
+	if value, key := r.lookup(resource.Tags); value != "" {
+		return value, fmt.Sprintf("tag:%s", key)
+	}
+
+	if !r.Inherit {
+		return "", ""
+	}
+	for _, parent := range parents {
+		if value, key := r.lookup(parent.Tags); value != "" {
+			return value, fmt.Sprintf("tag:%s:%s", parent.Level, key)
+		}
+	}
+
+	return "", ""
+}
+
+func (r *BackstageMappingRule) lookup(tags map[string]string) (string, string) {
+	for _, alias := range r.Tags {
+		for key, value := range tags {
+			if strings.EqualFold(key, alias) && strings.TrimSpace(value) != "" {
+				return strings.TrimSpace(value), key
+			}
+		}
+	}
+	return "", ""
+}
+
+// SanitizeNamespace
+// Adapta o valor às regras de namespace do Backstage: minúsculo, apenas [a-z0-9] separados por -
+func SanitizeNamespace(namespace string) string {
+	return strings.NewReplacer("_", "-", ".", "-").Replace(SanitizeName(namespace))
+}
@@ -0,0 +1,122 @@
+package entity
+
+import "testing"
+
+func TestBackstageMappingRuleResolve(t *testing.T) {
+
+	group := &CloudResource{Level: CloudResourceGroup, Tags: map[string]string{"Team": "rg-team"}}
+	subscription := &CloudResource{Level: CloudResourceAccount, Tags: map[string]string{"owner": "sub-owner", "team": "sub-team"}}
+
+	tests := []struct {
+		name       string
+		rule       BackstageMappingRule
+		tags       map[string]string
+		parents    []*CloudResource
+		wantValue  string
+		wantSource string
+	}{
+		{
+			name:       "first alias wins",
+			rule:       BackstageMappingRule{Tags: []string{"owner", "team"}},
+			tags:       map[string]string{"team": "platform", "owner": "data"},
+			wantValue:  "data",
+			wantSource: "tag:owner",
+		},
+		{
+			name:       "aliases ignore case",
+			rule:       BackstageMappingRule{Tags: []string{"owner", "team"}},
+			tags:       map[string]string{"TEAM": "platform"},
+			wantValue:  "platform",
+			wantSource: "tag:TEAM",
+		},
+		{
+			name:       "blank values are skipped",
+			rule:       BackstageMappingRule{Tags: []string{"owner", "team"}},
+			tags:       map[string]string{"owner": "  ", "team": " platform "},
+			wantValue:  "platform",
+			wantSource: "tag:team",
+		},
+		{
+			name:    "parents are ignored without inherit",
+			rule:    BackstageMappingRule{Tags: []string{"team"}},
+			parents: []*CloudResource{group, subscription},
+		},
+		{
+			name:       "closest parent wins",
+			rule:       BackstageMappingRule{Tags: []string{"team"}, Inherit: true},
+			parents:    []*CloudResource{group, subscription},
+			wantValue:  "rg-team",
+			wantSource: "tag:group:Team",
+		},
+		{
+			name:       "resource tag wins over parents",
+			rule:       BackstageMappingRule{Tags: []string{"team"}, Inherit: true},
+			tags:       map[string]string{"team": "platform"},
+			parents:    []*CloudResource{group, subscription},
+			wantValue:  "platform",
+			wantSource: "tag:team",
+		},
+		{
+			name:       "alias priority applies per level",
+			rule:       BackstageMappingRule{Tags: []string{"owner", "team"}, Inherit: true},
+			parents:    []*CloudResource{group, subscription},
+			wantValue:  "rg-team",
+			wantSource: "tag:group:Team",
+		},
+		{
+			name:    "default is not applied",
+			rule:    BackstageMappingRule{Tags: []string{"owner"}, Default: "platform"},
+			parents: []*CloudResource{group},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			value, source := tt.rule.Resolve(&CloudResource{Level: CloudResourceItem, Tags: tt.tags}, tt.parents)
+			if value != tt.wantValue || source != tt.wantSource {
+				t.Errorf("Resolve() = (%q, %q), want (%q, %q)", value, source, tt.wantValue, tt.wantSource)
+			}
+		})
+	}
+}
+
+func TestBackstageMappingDefaults(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		mapping BackstageMapping
+		owner   []string
+		system  []string
+	}{
+		{name: "empty", mapping: BackstageMapping{}, owner: []string{"owner"}, system: []string{"system"}},
+		{
+			name:    "configured aliases",
+			mapping: BackstageMapping{Owner: BackstageMappingRule{Tags: []string{"team", "squad"}}, System: BackstageMappingRule{Tags: []string{"app"}}},
+			owner:   []string{"team", "squad"},
+			system:  []string{"app"},
+		},
+	}
+
+	equal := func(a, b []string) bool {
+		if len(a) != len(b) {
+			return false
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				return false
+			}
+		}
+		return true
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.mapping.GetOwner().Tags; !equal(got, tt.owner) {
+				t.Errorf("GetOwner().Tags = %v, want %v", got, tt.owner)
+			}
+			if got := tt.mapping.GetSystem().Tags; !equal(got, tt.system) {
+				t.Errorf("GetSystem().Tags = %v, want %v", got, tt.system)
+			}
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package entity
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// DefaultNamingTemplate mantém o nome do recurso no provedor
+const DefaultNamingTemplate = "{name}"
+
+// BackstageNaming
+// Template é o template padrão e Templates sobrescreve o template por nível (organization, account, group ou resource).
+// Variáveis: {provider}, {account}, {level}, {type}, {location}, {name} e as do escopo do provedor. Exemplo {subscription}, {subscription_id} e {rg} na Azure.
+// Variáveis sem valor ficam vazias e os separadores repetidos são removidos na sanitização.
+// ClaimTTL validade da reivindicação de um nome, renovada a cada sincronização que emite a entidade
+type BackstageNaming struct {
+	Template  string            `json:"template,omitempty" mapstructure:"template"`
+	Templates map[string]string `json:"templates,omitempty" mapstructure:"templates"`
+	ClaimTTL  time.Duration     `json:"claim_ttl,omitempty" mapstructure:"claim_ttl"`
+}
+
+// GetClaimTTL
+// Padrão de 7 dias. Precisa ser maior que o intervalo entre sincronizações
+func (n *BackstageNaming) GetClaimTTL() time.Duration {
+	if n.ClaimTTL <= 0 {
+		return 7 * 24 * time.Hour
+	}
+	return n.ClaimTTL
+}
+
+var namingVariable = regexp.MustCompile(`\{([a-z_]+)\}`)
+
+// GetTemplate
+// Template do nível, o template padrão ou {name} quando nenhum foi configurado
+func (n *BackstageNaming) GetTemplate(level string) string {
+	if template, ok := n.Templates[level]; ok && template != "" {
+		return template
+	}
+	if n.Template != "" {
+		return n.Template
+	}
+	return DefaultNamingTemplate
+}
+
+// Render
+// Substitui as variáveis do template do nível. O resultado ainda precisa ser sanitizado
+func (n *BackstageNaming) Render(level string, vars map[string]string) string {
+	return namingVariable.ReplaceAllStringFunc(n.GetTemplate(level), func(match string) string {
+		return vars[match[1:len(match)-1]]
+	})
+}
+
+func (n *BackstageNaming) Validate() error {
+
+	levels := map[string]bool{
+		CloudResourceOrganization: true,
+		CloudResourceAccount:      true,
+		CloudResourceGroup:        true,
+		CloudResourceItem:         true,
+	}
+
+	if n.Template != "" && !strings.Contains(n.Template, "{name}") {
+		return fmt.Errorf("the naming template %s requires the {name} variable", n.Template)
+	}
+	for level, template := range n.Templates {
+		if !levels[level] {
+			return fmt.Errorf("the naming level %s is not supported", level)
+		}
+		if !strings.Contains(template, "{name}") {
+			return fmt.Errorf("the naming template %s of the level %s requires the {name} variable", template, level)
+		}
+	}
+
+	return nil
+}
+
+// SanitizeName
+// Adapta o nome às regras do Backstage: minúsculo, apenas [a-z0-9] separados por um único - _ ou .,
+// sem separadores nas pontas e com até 63 caracteres. Os demais caracteres viram -
+func SanitizeName(name string) string {
+
+	var b strings.Builder
+	var separator rune
+	for _, r := range strings.ToLower(name) {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') {
+			if separator != 0 && b.Len() > 0 {
+				b.WriteRune(separator)
+			}
+			separator = 0
+			b.WriteRune(r)
+			continue
+		}
+		if separator == 0 {
+			separator = '-'
+			if r == '_' || r == '.' {
+				separator = r
+			}
+		}
+	}
+
+	result := b.String()
+	if len(result) > 63 {
+		result = strings.TrimRight(result[:63], "-_.")
+	}
+	return result
+}
+
+// SuffixName
+// Nome com o sufixo de 8 caracteres do hash do ID, usado quando o nome já pertence a outro recurso.
+// O sufixo depende apenas do ID, então o mesmo recurso recebe sempre o mesmo nome
+func SuffixName(name, id string) string {
+
+	sum := sha256.Sum256([]byte(strings.ToLower(id)))
+	suffix := hex.EncodeToString(sum[:])[:8]
+
+	if len(name) > 63-len(suffix)-1 {
+		name = strings.TrimRight(name[:63-len(suffix)-1], "-_.")
+	}
+	if name == "" {
+		return suffix
+	}
+	return fmt.Sprintf("%s-%s", name, suffix)
+}
@@ -0,0 +1,132 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBackstageNamingRender(t *testing.T) {
+
+	vars := map[string]string{
+		"provider":     "azure",
+		"subscription": "prod",
+		"rg":           "rg-app",
+		"name":         "storage01",
+	}
+
+	tests := []struct {
+		name   string
+		naming BackstageNaming
+		level  string
+		want   string
+	}{
+		{name: "default template", naming: BackstageNaming{}, level: CloudResourceItem, want: "storage01"},
+		{name: "global template", naming: BackstageNaming{Template: "{subscription}-{name}"}, level: CloudResourceItem, want: "prod-storage01"},
+		{
+			name:   "level template overrides the global one",
+			naming: BackstageNaming{Template: "{subscription}-{name}", Templates: map[string]string{CloudResourceItem: "{rg}-{name}"}},
+			level:  CloudResourceItem,
+			want:   "rg-app-storage01",
+		},
+		{
+			name:   "other levels keep the global template",
+			naming: BackstageNaming{Template: "{subscription}-{name}", Templates: map[string]string{CloudResourceItem: "{rg}-{name}"}},
+			level:  CloudResourceGroup,
+			want:   "prod-storage01",
+		},
+		{name: "unknown variables are empty", naming: BackstageNaming{Template: "{location}-{name}"}, level: CloudResourceItem, want: "-storage01"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.naming.Render(tt.level, vars); got != tt.want {
+				t.Errorf("Render() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackstageNamingValidate(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		naming  BackstageNaming
+		wantErr bool
+	}{
+		{name: "empty", naming: BackstageNaming{}},
+		{name: "template with name", naming: BackstageNaming{Template: "{subscription}-{name}"}},
+		{name: "template without name", naming: BackstageNaming{Template: "{subscription}"}, wantErr: true},
+		{name: "level template with name", naming: BackstageNaming{Templates: map[string]string{CloudResourceAccount: "sub-{name}"}}},
+		{name: "level template without name", naming: BackstageNaming{Templates: map[string]string{CloudResourceAccount: "sub"}}, wantErr: true},
+		{name: "unknown level", naming: BackstageNaming{Templates: map[string]string{"tenant": "{name}"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.naming.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSanitizeName(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{name: "valid", input: "storage01", want: "storage01"},
+		{name: "lower case", input: "Storage01", want: "storage01"},
+		{name: "spaces", input: "my storage account", want: "my-storage-account"},
+		{name: "repeated separators", input: "rg--app__prod", want: "rg-app_prod"},
+		{name: "separators at the ends", input: "-_storage01._", want: "storage01"},
+		{name: "invalid characters", input: "app/prod@east", want: "app-prod-east"},
+		{name: "only invalid characters", input: "@@@", want: ""},
+		{name: "truncated", input: strings.Repeat("a", 62) + "-bc", want: strings.Repeat("a", 62)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeName(tt.input)
+			if got != tt.want {
+				t.Errorf("SanitizeName(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+			if got != "" && !ValidName(got) {
+				t.Errorf("SanitizeName(%q) = %q is not a valid name", tt.input, got)
+			}
+		})
+	}
+}
+
+func TestSuffixName(t *testing.T) {
+
+	id := "/subscriptions/1/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/storage01"
+
+	tests := []struct {
+		name  string
+		input string
+		id    string
+	}{
+		{name: "short name", input: "storage01", id: id},
+		{name: "long name", input: strings.Repeat("a", 63), id: id},
+		{name: "empty name", input: "", id: id},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SuffixName(tt.input, tt.id)
+			if !ValidName(got) {
+				t.Errorf("SuffixName(%q) = %q is not a valid name", tt.input, got)
+			}
+			if got != SuffixName(tt.input, strings.ToUpper(tt.id)) {
+				t.Errorf("SuffixName(%q) depends on the case of the ID", tt.input)
+			}
+		})
+	}
+
+	if SuffixName("storage01", id) == SuffixName("storage01", id+"2") {
+		t.Error("SuffixName() returned the same name for different IDs")
+	}
+}
@@ -0,0 +1,54 @@
+package entity
+
+import "testing"
+
+func TestQualifyRef(t *testing.T) {
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{name: "empty", ref: "", want: ""},
+		{name: "name", ref: "platform", want: "group:default/platform"},
+		{name: "namespace and name", ref: "prod/platform", want: "group:prod/platform"},
+		{name: "kind and name", ref: "user:jdoe", want: "user:default/jdoe"},
+		{name: "full ref", ref: "User:prod/jdoe", want: "user:prod/jdoe"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := QualifyRef(tt.ref, "group", BackstageDefaultNamespace); got != tt.want {
+				t.Errorf("QualifyRef(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSanitizeRef(t *testing.T) {
+
+	tests := []struct {
+		name string
+		ref  string
+		want string
+	}{
+		{name: "empty", ref: "", want: ""},
+		{name: "valid name", ref: "platform", want: "group:default/platform"},
+		{name: "display name", ref: "Platform Team", want: "group:default/platform-team"},
+		{name: "email", ref: "user:jdoe@contoso.com", want: "user:default/jdoe-contoso.com"},
+		{name: "invalid namespace", ref: "Prod_East/platform", want: "group:prod-east/platform"},
+		{name: "no valid character", ref: "@@@", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SanitizeRef(tt.ref, "group", BackstageDefaultNamespace)
+			if got != tt.want {
+				t.Errorf("SanitizeRef(%q) = %q, want %q", tt.ref, got, tt.want)
+			}
+			if got != "" && !ValidRef(got) {
+				t.Errorf("SanitizeRef(%q) = %q is not a valid ref", tt.ref, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+package entity
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Regras de formato do catálogo do Backstage (@backstage/catalog-model)
+var (
+	backstageKind      = regexp.MustCompile(`^[a-zA-Z][a-zA-Z0-9]*$`)
+	backstageName      = regexp.MustCompile(`^([a-zA-Z0-9]+[-_.])*[a-zA-Z0-9]+$`)
+	backstageNamespace = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+	backstagePrefix    = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*(\.[a-z0-9]+(-[a-z0-9]+)*)*$`)
+	backstageTag       = regexp.MustCompile(`^[a-z0-9:+#]+(-[a-z0-9:+#]+)*$`)
+)
+
+// backstageKinds kinds emitidos pelo coletor
+var backstageKinds = map[string]bool{
+	"Resource": true,
+	"Domain":   true,
+	"System":   true,
+	"Group":    true,
+	"User":     true,
+}
+
+// ValidName
+// Nome de entidade, valor de label ou parte final de uma chave: até 63 caracteres [a-zA-Z0-9] separados por - _ ou .
+func ValidName(name string) bool {
+	return len(name) >= 1 && len(name) <= 63 && backstageName.MatchString(name)
+}
+
+// ValidNamespace
+// Namespace no formato de label DNS em minúsculas
+func ValidNamespace(namespace string) bool {
+	return len(namespace) >= 1 && len(namespace) <= 63 && backstageNamespace.MatchString(namespace)
+}
+
+// ValidKey
+// Chave de label ou anotação: [prefixo/]nome, com prefixo no formato de subdomínio DNS
+func ValidKey(key string) bool {
+	prefix, name, found := strings.Cut(key, "/")
+	if !found {
+		return ValidName(key)
+	}
+	return len(prefix) >= 1 && len(prefix) <= 253 && backstagePrefix.MatchString(prefix) && ValidName(name)
+}
+
+// ValidLabel
+// Label aceito pelo catálogo. O valor pode ser vazio
+func ValidLabel(key, value string) bool {
+	return ValidKey(key) && (value == "" || ValidName(value))
+}
+
+// ValidTag
+// Tag em minúsculas de até 63 caracteres
+func ValidTag(tag string) bool {
+	return len(tag) >= 1 && len(tag) <= 63 && backstageTag.MatchString(tag)
+}
+
+// ValidRef
+// Referência completa kind:namespace/name
+func ValidRef(ref string) bool {
+	kind, rest, found := strings.Cut(ref, ":")
+	if !found {
+		return false
+	}
+	namespace, name, found := strings.Cut(rest, "/")
+	if !found {
+		return false
+	}
+	return backstageKind.MatchString(kind) && ValidNamespace(namespace) && ValidName(name)
+}
+
+// Validate
+// Confere a entidade com as regras do catálogo do Backstage e retorna todas as violações encontradas
+func (e *BackstageEntity) Validate() error {
+
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if e.APIVersion != BackstageAPIVersion {
+		fail("the apiVersion %q is not supported", e.APIVersion)
+	}
+	if !backstageKinds[e.Kind] {
+		fail("the kind %q is not supported", e.Kind)
+	}
+
+	if !ValidName(e.Metadata.Name) {
+		fail("the name %q is not valid", e.Metadata.Name)
+	}
+	if !ValidNamespace(e.Metadata.Namespace) {
+		fail("the namespace %q is not valid", e.Metadata.Namespace)
+	}
+	for key, value := range e.Metadata.Labels {
+		if !ValidLabel(key, value) {
+			fail("the label %q=%q is not valid", key, value)
+		}
+	}
+	for key := range e.Metadata.Annotations {
+		if !ValidKey(key) {
+			fail("the annotation %q is not valid", key)
+		}
+	}
+	for _, tag := range e.Metadata.Tags {
+		if !ValidTag(tag) {
+			fail("the tag %q is not valid", tag)
+		}
+	}
+	for _, link := range e.Metadata.Links {
+		if u, err := url.Parse(link.URL); err != nil || u.Scheme == "" || u.Host == "" {
+			fail("the link %q is not a valid URL", link.URL)
+		}
+	}
+
+	ref := func(field, value string, required bool) {
+		if value == "" {
+			if required {
+				fail("the spec.%s cannot be empty", field)
+			}
+			return
+		}
+		if !ValidRef(value) {
+			fail("the spec.%s %q is not a kind:namespace/name reference", field, value)
+		}
+	}
+	refs := func(field string, values []string) {
+		for _, value := range values {
+			ref(field, value, true)
+		}
+	}
+
+	switch e.Kind {
+	case "Group":
+		if e.Spec.Type == "" {
+			fail("the spec.type cannot be empty")
+		}
+		refs("children", e.Spec.Children)
+	case "User":
+		refs("memberOf", e.Spec.MemberOf)
+	case "Domain":
+		ref("owner", e.Spec.Owner, true)
+		ref("subdomainOf", e.Spec.SubdomainOf, false)
+	default:
+		if e.Spec.Type == "" {
+			fail("the spec.type cannot be empty")
+		}
+		ref("owner", e.Spec.Owner, true)
+		ref("system", e.Spec.System, false)
+		refs("dependsOn", e.Spec.DependsOn)
+		refs("dependencyOf", e.Spec.DependencyOf)
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("%s: %w", e.Ref(), err)
+	}
+	return nil
+}
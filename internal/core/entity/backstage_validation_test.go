@@ -0,0 +1,94 @@
+package entity
+
+import "testing"
+
+func TestBackstageEntityValidate(t *testing.T) {
+
+	resource := func(change func(e *BackstageEntity)) BackstageEntity {
+		e := NewBackstageEntity("Resource", "storage01")
+		e.Spec.Type = "storage-account"
+		e.Spec.Owner = "group:default/platform"
+		if change != nil {
+			change(&e)
+		}
+		return e
+	}
+
+	tests := []struct {
+		name    string
+		entity  BackstageEntity
+		wantErr bool
+	}{
+		{name: "valid resource", entity: resource(nil)},
+		{name: "unsupported kind", entity: resource(func(e *BackstageEntity) { e.Kind = "Component" }), wantErr: true},
+		{name: "unsupported apiVersion", entity: resource(func(e *BackstageEntity) { e.APIVersion = "v1" }), wantErr: true},
+		{name: "invalid name", entity: resource(func(e *BackstageEntity) { e.Metadata.Name = "storage 01" }), wantErr: true},
+		{name: "invalid namespace", entity: resource(func(e *BackstageEntity) { e.Metadata.Namespace = "Prod_01" }), wantErr: true},
+		{name: "invalid label", entity: resource(func(e *BackstageEntity) { e.Metadata.Labels["env"] = "prod east" }), wantErr: true},
+		{name: "prefixed annotation", entity: resource(func(e *BackstageEntity) { e.Metadata.Annotations["cloud-collector/resource-id"] = "/subscriptions/1" })},
+		{name: "invalid annotation", entity: resource(func(e *BackstageEntity) { e.Metadata.Annotations["Cloud Collector/id"] = "1" }), wantErr: true},
+		{name: "invalid tag", entity: resource(func(e *BackstageEntity) { e.Metadata.Tags = []string{"Prod"} }), wantErr: true},
+		{name: "invalid link", entity: resource(func(e *BackstageEntity) { e.Metadata.Links = []BackstageLink{{URL: "portal"}} }), wantErr: true},
+		{name: "missing owner", entity: resource(func(e *BackstageEntity) { e.Spec.Owner = "" }), wantErr: true},
+		{name: "unqualified owner", entity: resource(func(e *BackstageEntity) { e.Spec.Owner = "platform" }), wantErr: true},
+		{name: "missing type", entity: resource(func(e *BackstageEntity) { e.Spec.Type = "" }), wantErr: true},
+		{name: "unqualified dependsOn", entity: resource(func(e *BackstageEntity) { e.Spec.DependsOn = []string{"vnet01"} }), wantErr: true},
+		{name: "qualified dependsOn", entity: resource(func(e *BackstageEntity) { e.Spec.DependsOn = []string{"resource:default/vnet01"} })},
+		{
+			name: "domain without type",
+			entity: resource(func(e *BackstageEntity) {
+				e.Kind = "Domain"
+				e.Spec.Type = ""
+			}),
+		},
+		{
+			name: "group requires type",
+			entity: resource(func(e *BackstageEntity) {
+				e.Kind = "Group"
+				e.Spec.Type = ""
+				e.Spec.Owner = ""
+			}),
+			wantErr: true,
+		},
+		{
+			name: "user with memberOf",
+			entity: resource(func(e *BackstageEntity) {
+				e.Kind = "User"
+				e.Spec.MemberOf = []string{"group:default/platform"}
+			}),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.entity.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidRef(t *testing.T) {
+
+	tests := []struct {
+		ref  string
+		want bool
+	}{
+		{ref: "group:default/platform", want: true},
+		{ref: "resource:prod/storage01", want: true},
+		{ref: "platform", want: false},
+		{ref: "group:platform", want: false},
+		{ref: "default/platform", want: false},
+		{ref: "group:Prod/platform", want: false},
+		{ref: "group:default/plat form", want: false},
+		{ref: "group:default/", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ref, func(t *testing.T) {
+			if got := ValidRef(tt.ref); got != tt.want {
+				t.Errorf("ValidRef(%q) = %v, want %v", tt.ref, got, tt.want)
+			}
+		})
+	}
+}
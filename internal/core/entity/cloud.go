@@ -3,6 +3,10 @@ package entity
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
@@ -13,14 +17,489 @@ type AzureProviderInterface interface {
 	ListResourcesByTag(ctx context.Context, tagKey, tagValue string) ([]*armresources.GenericResourceExpanded, error)
 	ListResourcesByResourceGroup(ctx context.Context, rsg string) ([]*armresources.GenericResourceExpanded, error)
 	ListResources(ctx context.Context) ([]*armresources.GenericResourceExpanded, error)
+	ListSubscriptions(ctx context.Context) ([]*armsubscriptions.Subscription, error)
 	FilterResources(ctx context.Context, name ...string) ([]*armresources.ResourceGroup, error)
+	GetResourceGroup(ctx context.Context, subscriptionID, name string) (*armresources.ResourceGroup, error)
+	Query(ctx context.Context, query string) ([]map[string]interface{}, error)
+	PageResources(ctx context.Context, filter *AzureResourceFilter) <-chan AzureResourcePage
+	GetResourceByID(ctx context.Context, id string) (*AzureResourceDetail, error)
+	ListResourceProperties(ctx context.Context, ids []string) (map[string]interface{}, error)
+	ListChildResources(ctx context.Context, parentID string, path string) ([]*armresources.GenericResourceExpanded, error)
+	ListManagementGroups(ctx context.Context) ([]*AzureManagementGroup, error)
+	ListDirectoryGroups(ctx context.Context) ([]*AzureDirectoryGroup, error)
+	ListRoleAssignments(ctx context.Context, subscriptionID string) ([]*AzureRoleAssignment, error)
+	ListActivityLog(ctx context.Context, subscriptionID string, since time.Time) ([]*AzureActivityEvent, error)
 }
 
+// ErrSubscriptionNotCollected a assinatura não está entre as assinaturas coletadas pela conta
+var ErrSubscriptionNotCollected = errors.New("subscription not collected")
+
+// AzureActivityEvent
+// Evento administrativo do Activity Log
+// OperationName operação ARM. Exemplo Microsoft.Web/sites/write
+// Status status do evento. Exemplo Succeeded
+// Caller UPN do usuário ou object ID do principal que executou a operação
+// PrincipalID object ID do principal, lido das claims do token
+type AzureActivityEvent struct {
+	ResourceID     string    `json:"resource_id"`
+	OperationName  string    `json:"operation_name"`
+	Status         string    `json:"status"`
+	Caller         string    `json:"caller,omitempty"`
+	PrincipalID    string    `json:"principal_id,omitempty"`
+	EventTimestamp time.Time `json:"event_timestamp"`
+}
+
+// IsWrite
+// Operação de criação ou alteração concluída com sucesso
+func (e *AzureActivityEvent) IsWrite() bool {
+	return strings.EqualFold(e.Status, "Succeeded") && strings.HasSuffix(strings.ToLower(e.OperationName), "/write")
+}
+
+// IsDelete
+// Operação de exclusão concluída com sucesso
+func (e *AzureActivityEvent) IsDelete() bool {
+	return strings.EqualFold(e.Status, "Succeeded") && strings.HasSuffix(strings.ToLower(e.OperationName), "/delete")
+}
+
+// AzureRoleAssignment
+// Atribuição de função RBAC. RoleDefinitionID contém apenas o GUID da definição
+type AzureRoleAssignment struct {
+	ID               string `json:"id"`
+	Scope            string `json:"scope"`
+	PrincipalID      string `json:"principal_id"`
+	PrincipalType    string `json:"principal_type,omitempty"`
+	RoleDefinitionID string `json:"role_definition_id"`
+}
+
+// AzureDirectoryGroup
+// Grupo do Entra ID lido pelo Microsoft Graph com os usuários membros diretos
+type AzureDirectoryGroup struct {
+	ID           string                `json:"id"`
+	DisplayName  string                `json:"display_name"`
+	Description  string                `json:"description,omitempty"`
+	Mail         string                `json:"mail,omitempty"`
+	MailNickname string                `json:"mail_nickname,omitempty"`
+	Members      []*AzureDirectoryUser `json:"members,omitempty"`
+}
+
+// AzureDirectoryUser
+// Usuário do Entra ID membro de um AzureDirectoryGroup
+type AzureDirectoryUser struct {
+	ID                string `json:"id"`
+	DisplayName       string `json:"display_name"`
+	UserPrincipalName string `json:"user_principal_name"`
+	Mail              string `json:"mail,omitempty"`
+}
+
+// AzureManagementGroup
+// Grupo de gerenciamento na árvore do tenant
+// ParentID ID do grupo pai. Vazio no grupo raiz
+// Subscriptions IDs das assinaturas diretamente abaixo do grupo
+type AzureManagementGroup struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	DisplayName   string   `json:"display_name,omitempty"`
+	TenantID      string   `json:"tenant_id,omitempty"`
+	ParentID      string   `json:"parent_id,omitempty"`
+	Subscriptions []string `json:"subscriptions,omitempty"`
+}
+
+// AzureResourceDetail
+// Recurso completo retornado pelo GetByID com os segmentos do ID já separados
+// APIVersion versão usada na consulta, resolvida pela API de Providers para o tipo do recurso
+type AzureResourceDetail struct {
+	ID             string                        `json:"id"`
+	Name           string                        `json:"name"`
+	SubscriptionID string                        `json:"subscription_id"`
+	ResourceGroup  string                        `json:"resource_group,omitempty"`
+	Provider       string                        `json:"provider"`
+	Type           string                        `json:"type"`
+	APIVersion     string                        `json:"api_version"`
+	Resource       *armresources.GenericResource `json:"resource"`
+}
+
+// AzureResourceFilter
+// Filtro do iterador de recursos. Campos vazios não filtram
+// Subscriptions restringe a listagem às assinaturas informadas
+// Since restringe a listagem aos recursos criados ou alterados a partir do instante
+type AzureResourceFilter struct {
+	ResourceGroup string
+	TagKey        string
+	TagValue      string
+	Subscriptions []string
+	Since         time.Time
+}
+
+// IsEmpty
+// Filtro que lista todos os recursos de todas as assinaturas
+func (f *AzureResourceFilter) IsEmpty() bool {
+	return f.ResourceGroup == "" && f.TagKey == "" && f.TagValue == "" && len(f.Subscriptions) == 0 && f.Since.IsZero()
+}
+
+// AllowTime
+// Verifica se o recurso foi criado ou alterado a partir de Since
+func (f *AzureResourceFilter) AllowTime(created, changed *time.Time) bool {
+	if f.Since.IsZero() {
+		return true
+	}
+	return (created != nil && !created.Before(f.Since)) || (changed != nil && !changed.Before(f.Since))
+}
+
+// AllowSubscription
+// Verifica se a assinatura faz parte do filtro
+func (f *AzureResourceFilter) AllowSubscription(id string) bool {
+	if len(f.Subscriptions) == 0 {
+		return true
+	}
+	for _, v := range f.Subscriptions {
+		if strings.EqualFold(v, id) {
+			return true
+		}
+	}
+	return false
+}
+
+// AzureResourcePage
+// Página do iterador de recursos. Err encerra a iteração e o canal é fechado em seguida
+type AzureResourcePage struct {
+	Resources []*armresources.GenericResourceExpanded
+	Err       error
+}
+
+// Backends de coleta do AzureRepository
+// arm lista os recursos pelo armresources.Client (padrão)
+// resourcegraph executa consultas KQL no Azure Resource Graph
+const (
+	AzureBackendARM           = "arm"
+	AzureBackendResourceGraph = "resourcegraph"
+)
+
+// AzureProvider
+// Name identifica a conta quando mais de um tenant é configurado
+// AllSubscriptions coleta todas as assinaturas habilitadas visíveis pela credencial
+// IncludeSubscriptions e ExcludeSubscriptions aceitam IDs ou nomes de assinaturas
+// Backend seleciona a forma de coleta: arm ou resourcegraph
+// AuthType seleciona a credencial. Vazio equivale a client_secret
+// TLS e Proxy configuram o transporte HTTP da credencial e dos clients ARM
+// Cloud seleciona a nuvem: public (padrão), china, usgovernment ou custom
+// Throttling configura o transporte compartilhado pelos clients ARM
+// Relationships regras de dependência adicionadas às regras padrão
+// ChildResources tipos pai cujos recursos filhos também são coletados
+// ManagementGroups lê a árvore de grupos de gerenciamento e emite cada grupo como Domain
+// Graph grupos do Entra ID importados como Group e User
+// Ownership owner derivado das atribuições de função RBAC
+// ActivityLog enriquecimento pelo criador do recurso registrado no Activity Log
+// Incremental sincronização das assinaturas pelas escritas e exclusões do Activity Log
+// EventGrid webhook que recebe as alterações de recursos das assinaturas da conta
 type AzureProvider struct {
-	Subscription      string `json:"subsription" binding:"required" mapstructure:"subscription_id"`
-	ApplicationID     string `json:"application_id" binding:"required" mapstructure:"application_id"`
-	ApplicationSecret string `json:"application_sceret" binding:"required" mapstructure:"application_secret"`
-	Tenant            string `json:"tenant" binding:"required" mapstructure:"tenant_id"`
+	Name                 string                  `json:"name" mapstructure:"name"`
+	Subscription         string                  `json:"subsription" binding:"required" mapstructure:"subscription_id"`
+	ApplicationID        string                  `json:"application_id" binding:"required" mapstructure:"application_id"`
+	ApplicationSecret    string                  `json:"application_sceret" binding:"required" mapstructure:"application_secret"`
+	Tenant               string                  `json:"tenant" binding:"required" mapstructure:"tenant_id"`
+	AllSubscriptions     bool                    `json:"all_subscriptions" mapstructure:"all_subscriptions"`
+	IncludeSubscriptions []string                `json:"include_subscriptions,omitempty" mapstructure:"include_subscriptions"`
+	ExcludeSubscriptions []string                `json:"exclude_subscriptions,omitempty" mapstructure:"exclude_subscriptions"`
+	Backend              string                  `json:"backend,omitempty" mapstructure:"backend"`
+	AuthType             string                  `json:"auth_type,omitempty" mapstructure:"auth_type"`
+	CertificatePath      string                  `json:"certificate_path,omitempty" mapstructure:"certificate_path"`
+	CertificatePassword  string                  `json:"-" mapstructure:"certificate_password"`
+	TokenFile            string                  `json:"token_file,omitempty" mapstructure:"token_file"`
+	TLS                  AzureTLS                `json:"tls,omitempty" mapstructure:"tls"`
+	Proxy                string                  `json:"proxy,omitempty" mapstructure:"proxy"`
+	Cloud                string                  `json:"cloud,omitempty" mapstructure:"cloud"`
+	CloudEndpoints       AzureCloudEndpoints     `json:"cloud_endpoints,omitempty" mapstructure:"cloud_endpoints"`
+	Throttling           AzureThrottling         `json:"throttling,omitempty" mapstructure:"throttling"`
+	Relationships        []AzureRelationshipRule `json:"relationships,omitempty" mapstructure:"relationships"`
+	ChildResources       []AzureChildResource    `json:"child_resources,omitempty" mapstructure:"child_resources"`
+	ManagementGroups     bool                    `json:"management_groups,omitempty" mapstructure:"management_groups"`
+	Graph                AzureGraph              `json:"graph,omitempty" mapstructure:"graph"`
+	Ownership            AzureOwnership          `json:"ownership,omitempty" mapstructure:"ownership"`
+	ActivityLog          AzureActivityLog        `json:"activity_log,omitempty" mapstructure:"activity_log"`
+	Incremental          AzureIncremental        `json:"incremental,omitempty" mapstructure:"incremental"`
+	EventGrid            AzureEventGrid          `json:"event_grid,omitempty" mapstructure:"event_grid"`
+}
+
+// AzureGraph
+// Coleta de grupos e usuários do Entra ID pelo Microsoft Graph
+// BaseURL endpoint da API com a versão. Vazio usa o endpoint da nuvem configurada. Pode apontar para um fake local
+// Scope escopo do token. Vazio usa <esquema>://<host do BaseURL>/.default
+// Groups grupos importados pelo object ID, displayName ou mailNickname. Vazio desabilita a coleta
+type AzureGraph struct {
+	BaseURL string   `json:"base_url,omitempty" mapstructure:"base_url"`
+	Scope   string   `json:"scope,omitempty" mapstructure:"scope"`
+	Groups  []string `json:"groups,omitempty" mapstructure:"groups"`
+}
+
+// Enabled
+// A coleta do Graph só ocorre com ao menos um grupo configurado
+func (g *AzureGraph) Enabled() bool {
+	return len(g.Groups) > 0
+}
+
+// GetBaseURL
+// Retorna o BaseURL configurado ou o endpoint v1.0 do Graph da nuvem
+func (g *AzureGraph) GetBaseURL(cloud string) string {
+	if g.BaseURL != "" {
+		return strings.TrimSuffix(g.BaseURL, "/")
+	}
+
+	switch cloud {
+	case AzureCloudChina:
+		return "https://microsoftgraph.chinacloudapi.cn/v1.0"
+	case AzureCloudUSGovernment:
+		return "https://graph.microsoft.us/v1.0"
+	}
+	return "https://graph.microsoft.com/v1.0"
+}
+
+// GetScope
+// Retorna o Scope configurado ou o escopo .default do host do BaseURL
+func (g *AzureGraph) GetScope(cloud string) string {
+	if g.Scope != "" {
+		return g.Scope
+	}
+
+	u, err := url.Parse(g.GetBaseURL(cloud))
+	if err != nil {
+		return "https://graph.microsoft.com/.default"
+	}
+	return fmt.Sprintf("%s://%s/.default", u.Scheme, u.Host)
+}
+
+// AzureOwnership
+// Owner derivado das atribuições de função quando o recurso não possui a tag owner.
+// Os escopos são avaliados do recurso para o grupo de recursos e depois a assinatura
+// Roles funções consideradas em ordem de prioridade, pelo nome embutido ou GUID da definição. Padrão Owner e Contributor
+// Principals mapeia o object ID do principal para o nome do grupo no catálogo.
+// Sem mapeamento, apenas principais do tipo grupo importados em graph.groups são usados
+type AzureOwnership struct {
+	Enabled    bool              `json:"enabled,omitempty" mapstructure:"enabled"`
+	Roles      []string          `json:"roles,omitempty" mapstructure:"roles"`
+	Principals map[string]string `json:"principals,omitempty" mapstructure:"principals"`
+}
+
+// azureBuiltInRoles GUIDs das funções embutidas aceitas pelo nome em ownership.roles
+var azureBuiltInRoles = map[string]string{
+	"owner":       "8e3af657-a8ff-443c-a75c-2fe8c4bcb635",
+	"contributor": "b24988ac-6180-42e0-ab88-20f7382dd24c",
+}
+
+// AzureOwnershipRole
+// Função considerada pelo resolvedor com o nome usado na anotação
+type AzureOwnershipRole struct {
+	Name             string
+	RoleDefinitionID string
+}
+
+// GetRoles
+// Converte Roles em GUIDs de definição, na ordem configurada
+func (o *AzureOwnership) GetRoles() []AzureOwnershipRole {
+	roles := o.Roles
+	if len(roles) == 0 {
+		roles = []string{"Owner", "Contributor"}
+	}
+
+	result := make([]AzureOwnershipRole, 0, len(roles))
+	for _, role := range roles {
+		id, ok := azureBuiltInRoles[strings.ToLower(role)]
+		if !ok {
+			id = strings.ToLower(role)
+		}
+		result = append(result, AzureOwnershipRole{Name: role, RoleDefinitionID: id})
+	}
+	return result
+}
+
+// AzureActivityLog
+// CreatedBy anota cada recurso com o principal da primeira escrita encontrada na janela e o sugere como owner
+// Lookback janela consultada no Activity Log. Padrão 30 dias, máximo de 90 dias (retenção do Activity Log)
+// CacheTTL validade dos criadores em cache por assinatura. Padrão 24 horas
+type AzureActivityLog struct {
+	CreatedBy bool          `json:"created_by,omitempty" mapstructure:"created_by"`
+	Lookback  time.Duration `json:"lookback,omitempty" mapstructure:"lookback"`
+	CacheTTL  time.Duration `json:"cache_ttl,omitempty" mapstructure:"cache_ttl"`
+}
+
+// azureActivityLogRetention retenção máxima do Activity Log
+const azureActivityLogRetention = 90 * 24 * time.Hour
+
+func (l *AzureActivityLog) GetLookback() time.Duration {
+	if l.Lookback <= 0 {
+		return 30 * 24 * time.Hour
+	}
+	if l.Lookback > azureActivityLogRetention {
+		return azureActivityLogRetention
+	}
+	return l.Lookback
+}
+
+func (l *AzureActivityLog) GetCacheTTL() time.Duration {
+	if l.CacheTTL <= 0 {
+		return 24 * time.Hour
+	}
+	return l.CacheTTL
+}
+
+// AzureIncremental
+// Na sincronização sem filtro, cada assinatura guarda um cursor no cache e as sincronizações seguintes
+// buscam apenas os recursos escritos ou excluídos no Activity Log desde o cursor
+// FullResyncInterval intervalo entre sincronizações completas de cada assinatura. Padrão 24 horas
+type AzureIncremental struct {
+	Enabled            bool          `json:"enabled,omitempty" mapstructure:"enabled"`
+	FullResyncInterval time.Duration `json:"full_resync_interval,omitempty" mapstructure:"full_resync_interval"`
+}
+
+func (i *AzureIncremental) GetFullResyncInterval() time.Duration {
+	if i.FullResyncInterval <= 0 {
+		return 24 * time.Hour
+	}
+	return i.FullResyncInterval
+}
+
+// AzureEventGrid
+// Secret valor esperado no query param secret da URL do webhook. Vazio recusa os eventos da conta
+type AzureEventGrid struct {
+	Secret string `json:"-" mapstructure:"secret"`
+}
+
+// AzureTLS
+// CAFile bundle PEM adicionado aos certificados do sistema
+// InsecureSkipVerify desabilita a verificação do certificado. Usar somente em desenvolvimento
+type AzureTLS struct {
+	CAFile             string `json:"ca_file,omitempty" mapstructure:"ca_file"`
+	InsecureSkipVerify bool   `json:"insecure_skip_verify,omitempty" mapstructure:"insecure_skip_verify"`
+}
+
+// Tipos de autenticação do AzureProvider (auth_type)
+// client_secret usa application_id e application_secret (padrão)
+// client_certificate usa application_id e o certificado PEM/PKCS12 em certificate_path
+// workload_identity troca o token federado de token_file (Kubernetes) pelo token do Entra ID
+// managed_identity usa a identidade da máquina. application_id seleciona uma identidade user-assigned
+// cli usa a sessão do Azure CLI
+// default usa a cadeia do DefaultAzureCredential (ambiente, workload identity, managed identity, CLI)
+const (
+	AzureAuthClientSecret      = "client_secret"
+	AzureAuthClientCertificate = "client_certificate"
+	AzureAuthWorkloadIdentity  = "workload_identity"
+	AzureAuthManagedIdentity   = "managed_identity"
+	AzureAuthCLI               = "cli"
+	AzureAuthDefault           = "default"
+)
+
+// Nuvens do Azure (cloud)
+// custom exige authority_host e resource_manager_endpoint em cloud_endpoints
+const (
+	AzureCloudPublic       = "public"
+	AzureCloudChina        = "china"
+	AzureCloudUSGovernment = "usgovernment"
+	AzureCloudCustom       = "custom"
+)
+
+// AzureCloudEndpoints
+// Endpoints da nuvem custom. Audience vazio usa o próprio endpoint do Resource Manager
+type AzureCloudEndpoints struct {
+	AuthorityHost           string `json:"authority_host,omitempty" mapstructure:"authority_host"`
+	ResourceManagerEndpoint string `json:"resource_manager_endpoint,omitempty" mapstructure:"resource_manager_endpoint"`
+	ResourceManagerAudience string `json:"resource_manager_audience,omitempty" mapstructure:"resource_manager_audience"`
+}
+
+// AzureThrottling
+// Orçamento de requisições ARM por assinatura e política de retry para HTTP 429/503
+// RequestsPerSecond e Burst definem o token bucket de cada assinatura
+// MaxRetries, MinBackoff e MaxBackoff controlam o backoff exponencial com jitter quando não há Retry-After
+// MaxRetryAfter limita a espera pedida pelo Retry-After, que também não fica abaixo de MinBackoff
+type AzureThrottling struct {
+	RequestsPerSecond float64       `json:"requests_per_second,omitempty" mapstructure:"requests_per_second"`
+	Burst             int           `json:"burst,omitempty" mapstructure:"burst"`
+	MaxRetries        int           `json:"max_retries,omitempty" mapstructure:"max_retries"`
+	MinBackoff        time.Duration `json:"min_backoff,omitempty" mapstructure:"min_backoff"`
+	MaxBackoff        time.Duration `json:"max_backoff,omitempty" mapstructure:"max_backoff"`
+	MaxRetryAfter     time.Duration `json:"max_retry_after,omitempty" mapstructure:"max_retry_after"`
+}
+
+// GetRequestsPerSecond
+// Padrão de 10 requisições por segundo por assinatura
+func (t *AzureThrottling) GetRequestsPerSecond() float64 {
+	if t.RequestsPerSecond <= 0 {
+		return 10
+	}
+	return t.RequestsPerSecond
+}
+
+func (t *AzureThrottling) GetBurst() int {
+	if t.Burst <= 0 {
+		return 20
+	}
+	return t.Burst
+}
+
+func (t *AzureThrottling) GetMaxRetries() int {
+	if t.MaxRetries <= 0 {
+		return 5
+	}
+	return t.MaxRetries
+}
+
+func (t *AzureThrottling) GetMinBackoff() time.Duration {
+	if t.MinBackoff <= 0 {
+		return time.Second
+	}
+	return t.MinBackoff
+}
+
+func (t *AzureThrottling) GetMaxBackoff() time.Duration {
+	if t.MaxBackoff <= 0 {
+		return time.Minute
+	}
+	return t.MaxBackoff
+}
+
+// GetMaxRetryAfter
+// Padrão de 2 minutos
+func (t *AzureThrottling) GetMaxRetryAfter() time.Duration {
+	if t.MaxRetryAfter <= 0 {
+		return 2 * time.Minute
+	}
+	return t.MaxRetryAfter
+}
+
+// AzureRelationshipRule
+// Regra que extrai dependências das propriedades ARM de um recurso
+// Type tipo ARM do recurso de origem. Exemplo Microsoft.Web/sites
+// Path caminho dentro de properties separado por ponto. O sufixo [] percorre listas. Exemplo ipConfigurations[].properties.subnet.id
+// Parent aponta a dependência para o recurso pai do ID encontrado (ex.: subnet -> virtual network)
+type AzureRelationshipRule struct {
+	Type   string `json:"type" mapstructure:"type"`
+	Path   string `json:"path" mapstructure:"path"`
+	Parent bool   `json:"parent,omitempty" mapstructure:"parent"`
+}
+
+// AzureChildResource
+// Recursos filhos enumerados para cada recurso do tipo ParentType
+// ParentType tipo ARM do recurso pai. Exemplo Microsoft.Sql/servers
+// Path caminho relativo ao ID do pai, alternando tipo e nome. Exemplo databases ou blobServices/default/containers
+type AzureChildResource struct {
+	ParentType string `json:"parent_type" mapstructure:"parent_type"`
+	Path       string `json:"path" mapstructure:"path"`
+}
+
+// ChildType
+// Tipo completo do filho, sem os nomes intermediários do Path. Exemplo Microsoft.Storage/storageAccounts/blobServices/containers
+func (c *AzureChildResource) ChildType() string {
+	types := []string{c.ParentType}
+	for i, segment := range strings.Split(strings.Trim(c.Path, "/"), "/") {
+		if i%2 == 0 {
+			types = append(types, segment)
+		}
+	}
+	return strings.Join(types, "/")
+}
+
+// AzureQuery
+// Consulta KQL enviada ao Azure Resource Graph
+type AzureQuery struct {
+	Query string `json:"query" binding:"required"`
 }
 
 type AzureSubscription struct {
@@ -35,28 +514,162 @@ type AzureSubscription struct {
 func (a *AzureProvider) Validate() error {
 
 	var err error = nil
-	if a.Subscription == "" {
+	if a.Subscription == "" && !a.AllSubscriptions {
 		err = errors.New("the Azure subscription cannot be empty")
 	}
 
-	if a.ApplicationID == "" {
-		err = errors.New("the Azure application ID cannot be empty")
+	switch a.GetAuthType() {
+	case AzureAuthClientSecret:
+		if a.ApplicationID == "" {
+			err = errors.New("the Azure application ID cannot be empty")
+		}
+
+		if a.ApplicationSecret == "" {
+			err = errors.New("the Azure application secret cannot be empty")
+		}
+
+		if a.Tenant == "" {
+			err = errors.New("the Azure tenant cannot be empty")
+		}
+	case AzureAuthClientCertificate:
+		if a.ApplicationID == "" {
+			err = errors.New("the Azure application ID cannot be empty")
+		}
+
+		if a.CertificatePath == "" {
+			err = errors.New("the Azure certificate path cannot be empty")
+		}
+
+		if a.Tenant == "" {
+			err = errors.New("the Azure tenant cannot be empty")
+		}
+	case AzureAuthWorkloadIdentity:
+		if a.ApplicationID == "" {
+			err = errors.New("the Azure application ID cannot be empty")
+		}
+
+		if a.TokenFile == "" {
+			err = errors.New("the Azure token file cannot be empty")
+		}
+
+		if a.Tenant == "" {
+			err = errors.New("the Azure tenant cannot be empty")
+		}
+	case AzureAuthManagedIdentity, AzureAuthCLI, AzureAuthDefault:
+		if a.ApplicationSecret != "" || a.CertificatePath != "" || a.TokenFile != "" {
+			err = fmt.Errorf("the Azure auth type %s does not use secrets, certificates or token files", a.AuthType)
+		}
+	default:
+		err = fmt.Errorf("the Azure auth type %s is not supported", a.AuthType)
+	}
+
+	if a.Backend != "" && a.Backend != AzureBackendARM && a.Backend != AzureBackendResourceGraph {
+		err = fmt.Errorf("the Azure backend %s is not supported", a.Backend)
 	}
 
-	if a.ApplicationSecret == "" {
-		err = errors.New("the Azure application secret cannot be empty")
+	switch a.GetCloud() {
+	case AzureCloudPublic, AzureCloudChina, AzureCloudUSGovernment:
+	case AzureCloudCustom:
+		if a.CloudEndpoints.AuthorityHost == "" || a.CloudEndpoints.ResourceManagerEndpoint == "" {
+			err = errors.New("the Azure custom cloud requires authority_host and resource_manager_endpoint")
+		}
+	default:
+		err = fmt.Errorf("the Azure cloud %s is not supported", a.Cloud)
 	}
 
-	if a.Tenant == "" {
-		err = errors.New("the Azure tenant cannot be empty")
+	for _, rule := range a.Relationships {
+		if rule.Type == "" || rule.Path == "" {
+			err = errors.New("the Azure relationship rule requires type and path")
+		}
+	}
+
+	for _, child := range a.ChildResources {
+		if !strings.Contains(child.ParentType, "/") || child.Path == "" || len(strings.Split(strings.Trim(child.Path, "/"), "/"))%2 == 0 {
+			err = fmt.Errorf("the Azure child resource %s/%s is not valid", child.ParentType, child.Path)
+		}
+	}
+
+	if a.Proxy != "" {
+		if u, e := url.Parse(a.Proxy); e != nil || u.Scheme == "" || u.Host == "" {
+			err = fmt.Errorf("the Azure proxy %s is not a valid URL", a.Proxy)
+		}
+	}
+
+	if a.Graph.BaseURL != "" {
+		if u, e := url.Parse(a.Graph.BaseURL); e != nil || u.Scheme == "" || u.Host == "" {
+			err = fmt.Errorf("the Azure graph base_url %s is not a valid URL", a.Graph.BaseURL)
+		}
 	}
 
 	return err
 }
 
+// GetCloud
+// Retorna a nuvem configurada ou public quando não configurada
+func (a *AzureProvider) GetCloud() string {
+	if a.Cloud == "" {
+		return AzureCloudPublic
+	}
+	return strings.ToLower(a.Cloud)
+}
+
+// GetPortalURL
+// Endereço do portal da nuvem configurada. Vazio na nuvem custom
+func (a *AzureProvider) GetPortalURL() string {
+	switch a.GetCloud() {
+	case AzureCloudPublic:
+		return "https://portal.azure.com"
+	case AzureCloudChina:
+		return "https://portal.azure.cn"
+	case AzureCloudUSGovernment:
+		return "https://portal.azure.us"
+	}
+	return ""
+}
+
+// GetAuthType
+// Retorna o tipo de autenticação ou client_secret quando não configurado
+func (a *AzureProvider) GetAuthType() string {
+	if a.AuthType == "" {
+		return AzureAuthClientSecret
+	}
+	return a.AuthType
+}
+
 func (a *AzureProvider) IsEmpty() bool {
 	return a.Subscription == "" &&
+		!a.AllSubscriptions &&
+		a.AuthType == "" &&
 		a.ApplicationID == "" &&
 		a.ApplicationSecret == "" &&
 		a.Tenant == ""
 }
+
+// AllowSubscription
+// Aplica as listas de inclusão e exclusão sobre o ID ou o nome da assinatura
+func (a *AzureProvider) AllowSubscription(id, name string) bool {
+
+	match := func(list []string) bool {
+		for _, v := range list {
+			if strings.EqualFold(v, id) || strings.EqualFold(v, name) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if len(a.IncludeSubscriptions) > 0 && !match(a.IncludeSubscriptions) {
+		return false
+	}
+
+	return !match(a.ExcludeSubscriptions)
+}
+
+// GetName
+// Retorna o nome da conta ou DefaultAccount quando não configurado
+func (a *AzureProvider) GetName() string {
+	if a.Name == "" {
+		return DefaultAccount
+	}
+	return a.Name
+}
@@ -0,0 +1,97 @@
+package entity
+
+import (
+	"encoding/json"
+	"regexp"
+	"strings"
+)
+
+// Tipos de evento do Event Grid tratados pelo webhook
+const (
+	EventGridSubscriptionValidation = "Microsoft.EventGrid.SubscriptionValidationEvent"
+	EventGridResourceWriteSuccess   = "Microsoft.Resources.ResourceWriteSuccess"
+	EventGridResourceDeleteSuccess  = "Microsoft.Resources.ResourceDeleteSuccess"
+)
+
+// EventGridEvent
+// Evento entregue pelo Event Grid. Aceita o schema do Event Grid (eventType, topic)
+// e o schema CloudEvents 1.0 (type, source)
+type EventGridEvent struct {
+	ID        string          `json:"id"`
+	Topic     string          `json:"topic,omitempty"`
+	Source    string          `json:"source,omitempty"`
+	Subject   string          `json:"subject,omitempty"`
+	EventType string          `json:"eventType,omitempty"`
+	Type      string          `json:"type,omitempty"`
+	EventTime string          `json:"eventTime,omitempty"`
+	Time      string          `json:"time,omitempty"`
+	Data      json.RawMessage `json:"data,omitempty"`
+}
+
+// EventGridValidationData
+// Dados do evento de validação da assinatura do webhook
+type EventGridValidationData struct {
+	ValidationCode string `json:"validationCode"`
+	ValidationURL  string `json:"validationUrl,omitempty"`
+}
+
+// EventGridResourceData
+// Dados dos eventos ResourceWriteSuccess e ResourceDeleteSuccess
+type EventGridResourceData struct {
+	ResourceURI      string `json:"resourceUri"`
+	OperationName    string `json:"operationName"`
+	Status           string `json:"status"`
+	SubscriptionID   string `json:"subscriptionId"`
+	ResourceProvider string `json:"resourceProvider,omitempty"`
+}
+
+// EventGridValidationResponse
+// Resposta do handshake de validação
+type EventGridValidationResponse struct {
+	ValidationResponse string `json:"validationResponse"`
+}
+
+var eventGridSubscription = regexp.MustCompile(`(?i)^/subscriptions/([^/]+)`)
+
+// GetType
+// Tipo do evento nos dois schemas
+func (e *EventGridEvent) GetType() string {
+	if e.EventType != "" {
+		return e.EventType
+	}
+	return e.Type
+}
+
+// GetTopic
+// Tópico do evento nos dois schemas
+func (e *EventGridEvent) GetTopic() string {
+	if e.Topic != "" {
+		return e.Topic
+	}
+	return e.Source
+}
+
+// SubscriptionID
+// Assinatura de origem do evento, lida do tópico. Exemplo /subscriptions/{id}
+func (e *EventGridEvent) SubscriptionID() string {
+	if match := eventGridSubscription.FindStringSubmatch(e.GetTopic()); match != nil {
+		return strings.ToLower(match[1])
+	}
+	return ""
+}
+
+// ParseEventGridEvents
+// O schema do Event Grid entrega uma lista de eventos e o CloudEvents pode entregar um único evento
+func ParseEventGridEvents(body []byte) ([]EventGridEvent, error) {
+
+	var events []EventGridEvent
+	if err := json.Unmarshal(body, &events); err == nil {
+		return events, nil
+	}
+
+	var event EventGridEvent
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, err
+	}
+	return []EventGridEvent{event}, nil
+}
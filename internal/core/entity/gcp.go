@@ -0,0 +1,82 @@
+package entity
+
+import (
+	"context"
+	"errors"
+	"strings"
+)
+
+type GCPProviderInterface interface {
+	GetProject(ctx context.Context, name string) (*GCPProject, error)
+	GetFolder(ctx context.Context, name string) (*GCPFolder, error)
+	ListProjects(ctx context.Context) ([]*GCPProject, error)
+	ListFolders(ctx context.Context) ([]*GCPFolder, error)
+	ListResourcesByLabel(ctx context.Context, labelKey, labelValue string) ([]*GCPResource, error)
+	ListResourcesByProject(ctx context.Context, project string) ([]*GCPResource, error)
+	ListResources(ctx context.Context) ([]*GCPResource, error)
+}
+
+// GCPProvider
+// Configuração de acesso ao GCP
+// Projects lista de projetos coletados. Quando vazia coleta todos os projetos visíveis pela credencial
+// Endpoint e ResourceManagerEndpoint permitem apontar os clientes para um serviço local
+type GCPProvider struct {
+	Organization            string   `json:"organization,omitempty" mapstructure:"organization"`
+	Projects                []string `json:"projects,omitempty" mapstructure:"projects"`
+	CredentialsFile         string   `json:"credentials_file" binding:"required" mapstructure:"credentials_file"`
+	Endpoint                string   `json:"endpoint,omitempty" mapstructure:"endpoint"`
+	ResourceManagerEndpoint string   `json:"resource_manager_endpoint,omitempty" mapstructure:"resource_manager_endpoint"`
+}
+
+type GCPProject struct {
+	Name        string            `json:"name" binding:"required"`
+	ProjectID   string            `json:"project_id" binding:"required"`
+	DisplayName string            `json:"display_name"`
+	Parent      string            `json:"parent,omitempty"`
+	State       string            `json:"state"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+type GCPFolder struct {
+	Name        string `json:"name" binding:"required"`
+	DisplayName string `json:"display_name"`
+	Parent      string `json:"parent,omitempty"`
+	State       string `json:"state"`
+}
+
+// GCPResource
+// Recurso retornado pelo Cloud Asset Inventory
+// Name nome completo do recurso. Exemplo //compute.googleapis.com/projects/p/zones/z/instances/vm
+// AssetType tipo do recurso. Exemplo compute.googleapis.com/Instance
+// Project projeto dono do recurso no formato projects/{número}
+type GCPResource struct {
+	Name        string            `json:"name" binding:"required"`
+	DisplayName string            `json:"display_name"`
+	AssetType   string            `json:"asset_type" binding:"required"`
+	Project     string            `json:"project"`
+	Folders     []string          `json:"folders,omitempty"`
+	Location    string            `json:"location,omitempty"`
+	State       string            `json:"state,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty"`
+}
+
+func (g *GCPProvider) Validate() error {
+
+	var err error = nil
+	if g.CredentialsFile == "" && g.Endpoint == "" {
+		err = errors.New("the GCP credentials file cannot be empty")
+	}
+
+	if g.Organization != "" && !strings.HasPrefix(g.Organization, "organizations/") {
+		err = errors.New("the GCP organization must be in the format organizations/{id}")
+	}
+
+	return err
+}
+
+func (g *GCPProvider) IsEmpty() bool {
+	return g.Organization == "" &&
+		len(g.Projects) == 0 &&
+		g.CredentialsFile == "" &&
+		g.Endpoint == ""
+}
@@ -0,0 +1,26 @@
+package entity
+
+import "testing"
+
+func TestGCPProviderValidate(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		provider GCPProvider
+		wantErr  bool
+	}{
+		{name: "credentials file", provider: GCPProvider{CredentialsFile: "key.json"}},
+		{name: "local endpoint", provider: GCPProvider{Endpoint: "http://localhost:8080/"}},
+		{name: "no credentials", provider: GCPProvider{Projects: []string{"app"}}, wantErr: true},
+		{name: "organization", provider: GCPProvider{CredentialsFile: "key.json", Organization: "organizations/1"}},
+		{name: "invalid organization", provider: GCPProvider{CredentialsFile: "key.json", Organization: "1"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.provider.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
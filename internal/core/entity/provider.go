@@ -0,0 +1,98 @@
+package entity
+
+import (
+	"context"
+)
+
+// CloudProviderInterface
+// Contrato comum dos provedores de nuvem consumido pelo BackstageService
+// ListCloudResources lista todos os recursos do provedor
+// FilterCloudResources aplica os filtros do Trigger (grupo/região/projeto e tags)
+// StreamCloudResources aplica os mesmos filtros e entrega os recursos página a página
+// GetParent retorna o recurso pai na hierarquia ou nil quando o recurso é a raiz
+type CloudProviderInterface interface {
+	Provider() CloudProvider
+	Account() string
+	ListCloudResources(ctx context.Context) ([]CloudResource, error)
+	FilterCloudResources(ctx context.Context, trigger *Trigger) ([]CloudResource, error)
+	StreamCloudResources(ctx context.Context, trigger *Trigger) <-chan CloudResourcePage
+	GetParent(ctx context.Context, resource *CloudResource) (*CloudResource, error)
+}
+
+// DefaultAccount nome da conta quando o provedor não possui um nome configurado
+const DefaultAccount = "default"
+
+// Níveis de um recurso normalizado na hierarquia do provedor
+const (
+	CloudResourceOrganization = "organization"
+	CloudResourceAccount      = "account"
+	CloudResourceGroup        = "group"
+	CloudResourceItem         = "resource"
+
+	// identidades do diretório do provedor (ex.: Entra ID)
+	CloudResourceDirectoryGroup = "directory_group"
+	CloudResourceDirectoryUser  = "directory_user"
+)
+
+// CloudResource
+// Recurso normalizado, independente do provedor de nuvem
+// Level nível na hierarquia: organization (grupo de gerenciamento), account (assinatura, conta, projeto), group (grupo de recursos, pasta) ou resource
+// Type tipo do recurso usado no spec do Backstage. Exemplo virtualmachines
+// Family família/serviço do tipo. Exemplo microsoft.compute
+// ParentID identificador do recurso pai, resolvido por GetParent
+// DependsOn recursos referenciados pelas propriedades do recurso (ex.: plano de um App Service)
+// Owner resolvido pelo provedor quando o recurso não possui a tag owner (ex.: atribuições RBAC)
+// Deleted recurso excluído no provedor. A entidade é publicada apenas para ser removida do catálogo
+// DisplayName, Email e MemberOf usados pelos níveis de diretório (grupos e usuários)
+// Links páginas do recurso no console do provedor
+// Scope nomes dos níveis que contêm o recurso, usados como variáveis do template de nome. Exemplo subscription e rg
+type CloudResource struct {
+	ID          string            `json:"id" binding:"required"`
+	Name        string            `json:"name" binding:"required"`
+	Type        string            `json:"type" binding:"required"`
+	Family      string            `json:"family,omitempty"`
+	Level       string            `json:"level" binding:"required"`
+	Provider    string            `json:"provider" binding:"required"`
+	Account     string            `json:"account,omitempty"`
+	Location    string            `json:"location,omitempty"`
+	State       string            `json:"state,omitempty"`
+	ParentID    string            `json:"parent_id,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty"`
+	DependsOn   []CloudReference  `json:"depends_on,omitempty"`
+	Owner       string            `json:"owner,omitempty"`
+	Deleted     bool              `json:"deleted,omitempty"`
+	DisplayName string            `json:"display_name,omitempty"`
+	Email       string            `json:"email,omitempty"`
+	MemberOf    []CloudReference  `json:"member_of,omitempty"`
+	Links       []CloudLink       `json:"links,omitempty"`
+	Scope       map[string]string `json:"scope,omitempty"`
+}
+
+// CloudLink
+// Link externo do recurso. Exemplo página do recurso no portal
+type CloudLink struct {
+	URL   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+// CloudReference
+// Referência a outro recurso do provedor pelo ID e pelo nome no provedor.
+// Type, Account e Scope permitem gerar o nome da entidade referenciada com o mesmo template de nome
+type CloudReference struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Type    string            `json:"type,omitempty"`
+	Account string            `json:"account,omitempty"`
+	Scope   map[string]string `json:"scope,omitempty"`
+}
+
+// CloudResourcePage
+// Página de recursos normalizados. Err encerra a iteração e o canal é fechado em seguida
+// Checkpoint registra que as páginas anteriores foram entregues, como os cursores da sincronização incremental.
+// O consumidor o chama somente depois de publicar todas as páginas recebidas
+type CloudResourcePage struct {
+	Resources  []CloudResource
+	Err        error
+	Checkpoint func(ctx context.Context) error
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+	"github.com/synera-br/golang-cloud-collector/pkg/otelpkg"
+)
+
+type AWSRepository struct {
+	Provider entity.AWSProvider
+	Tracer   *otelpkg.OtelPkgInstrument
+	Config   aws.Config
+	Clients  map[string]*resourcegroupstaggingapi.Client
+	STS      *sts.Client
+}
+
+func NewAWSRepository(provider *entity.AWSProvider, otl *otelpkg.OtelPkgInstrument) (entity.AWSProviderInterface, error) {
+
+	p := &AWSRepository{
+		Provider: *provider,
+		Tracer:   otl,
+		Clients:  make(map[string]*resourcegroupstaggingapi.Client),
+	}
+
+	ctxSpan, span := p.Tracer.Tracer.Start(context.Background(), "AWSRepository.NewAWSRepository")
+	defer span.End()
+
+	if err := p.Connection(ctxSpan, &p.Provider); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (a *AWSRepository) Connection(ctx context.Context, provider *entity.AWSProvider) error {
+
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AWSRepository.Connection")
+	defer span.End()
+
+	var err error
+
+	a.Config, err = config.LoadDefaultConfig(ctxSpan,
+		config.WithRegion(provider.Region),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(provider.AccessKeyID, provider.SecretAccessKey, provider.SessionToken)),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to load aws configuration: %w", err)
+	}
+
+	for _, region := range provider.GetRegions() {
+		a.Clients[region] = resourcegroupstaggingapi.NewFromConfig(a.Config, a.clientOptions(region))
+	}
+
+	a.STS = sts.NewFromConfig(a.Config, func(o *sts.Options) {
+		if provider.Endpoint != "" {
+			o.BaseEndpoint = aws.String(provider.Endpoint)
+		}
+	})
+
+	return nil
+}
+
+// clientOptions
+// Define a região do cliente e, quando configurado, o endpoint alternativo
+func (a *AWSRepository) clientOptions(region string) func(o *resourcegroupstaggingapi.Options) {
+	return func(o *resourcegroupstaggingapi.Options) {
+		o.Region = region
+		if a.Provider.Endpoint != "" {
+			o.BaseEndpoint = aws.String(a.Provider.Endpoint)
+		}
+	}
+}
+
+// client
+// Cliente da região. Clients é criado na conexão e não é alterado depois, então regiões fora da configuração
+// são rejeitadas em vez de receberem um cliente novo a cada valor informado na rota
+func (a *AWSRepository) client(region string) (*resourcegroupstaggingapi.Client, error) {
+	client, ok := a.Clients[region]
+	if !ok {
+		return nil, fmt.Errorf("aws region %s: %w", region, entity.ErrRegionNotFound)
+	}
+	return client, nil
+}
+
+func (a *AWSRepository) GetAccount(ctx context.Context, id string) (*entity.AWSAccount, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AWSRepository.GetAccount")
+	defer span.End()
+
+	identity, err := a.STS.GetCallerIdentity(ctxSpan, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get caller identity: %w", err)
+	}
+
+	if id != "" && id != aws.ToString(identity.Account) {
+		err := fmt.Errorf("aws account %s: %w", id, entity.ErrAccountNotFound)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return &entity.AWSAccount{
+		ID:     aws.ToString(identity.Account),
+		ARN:    aws.ToString(identity.Arn),
+		UserID: aws.ToString(identity.UserId),
+	}, nil
+}
+
+func (a *AWSRepository) ListResources(ctx context.Context) ([]*entity.AWSResource, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AWSRepository.ListResources")
+	defer span.End()
+
+	var result []*entity.AWSResource
+	for _, region := range a.Provider.GetRegions() {
+		resources, err := a.listResources(ctxSpan, region, nil)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		result = append(result, resources...)
+	}
+
+	return result, nil
+}
+
+func (a *AWSRepository) ListResourcesByRegion(ctx context.Context, region string) ([]*entity.AWSResource, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AWSRepository.ListResourcesByRegion")
+	defer span.End()
+
+	result, err := a.listResources(ctxSpan, region, nil)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (a *AWSRepository) ListResourcesByTag(ctx context.Context, tagKey, tagValue string) ([]*entity.AWSResource, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AWSRepository.ListResourcesByTag")
+	defer span.End()
+
+	filter := []types.TagFilter{{Key: aws.String(tagKey), Values: []string{tagValue}}}
+
+	var result []*entity.AWSResource
+	for _, region := range a.Provider.GetRegions() {
+		resources, err := a.listResources(ctxSpan, region, filter)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		result = append(result, resources...)
+	}
+
+	return result, nil
+}
+
+func (a *AWSRepository) listResources(ctx context.Context, region string, filter []types.TagFilter) ([]*entity.AWSResource, error) {
+
+	client, err := a.client(region)
+	if err != nil {
+		return nil, err
+	}
+
+	pager := resourcegroupstaggingapi.NewGetResourcesPaginator(client, &resourcegroupstaggingapi.GetResourcesInput{
+		TagFilters: filter,
+	})
+
+	var result []*entity.AWSResource
+	for pager.HasMorePages() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list resources: %w", err)
+		}
+
+		for _, mapping := range page.ResourceTagMappingList {
+			resource, err := parseAWSResource(mapping)
+			if err != nil {
+				return nil, err
+			}
+			result = append(result, resource)
+		}
+	}
+
+	return result, nil
+}
+
+// parseAWSResource
+// Converte o ARN e as tags retornadas pela AWS para entity.AWSResource
+// O recurso do ARN pode ser "tipo/nome", "tipo:nome" ou somente "nome"
+func parseAWSResource(mapping types.ResourceTagMapping) (*entity.AWSResource, error) {
+
+	parsed, err := arn.Parse(aws.ToString(mapping.ResourceARN))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse resource arn: %w", err)
+	}
+
+	resource := &entity.AWSResource{
+		ARN:       parsed.String(),
+		Name:      parsed.Resource,
+		Service:   parsed.Service,
+		Region:    parsed.Region,
+		AccountID: parsed.AccountID,
+		Tags:      make(map[string]string),
+	}
+
+	if i := strings.IndexAny(parsed.Resource, "/:"); i > 0 {
+		resource.Type = parsed.Resource[:i]
+		resource.Name = parsed.Resource[i+1:]
+	}
+
+	for _, tag := range mapping.Tags {
+		resource.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+
+	return resource, nil
+}
@@ -0,0 +1,250 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/resourcegroupstaggingapi/types"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// awsRequestRegion região da assinatura SigV4 da requisição
+var awsRequestRegion = regexp.MustCompile(`Credential=[^/]+/[^/]+/([^/]+)/`)
+
+// fakeAWS
+// Tagging API e STS locais. Cada região retorna dois recursos em duas páginas
+func fakeAWS(t *testing.T, account string) *httptest.Server {
+	t.Helper()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if !strings.HasSuffix(r.Header.Get("X-Amz-Target"), ".GetResources") {
+			w.Header().Set("Content-Type", "text/xml")
+			fmt.Fprintf(w, `<GetCallerIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+<GetCallerIdentityResult><Arn>arn:aws:iam::%s:user/collector</Arn><UserId>AIDA1</UserId><Account>%s</Account></GetCallerIdentityResult>
+<ResponseMetadata><RequestId>1</RequestId></ResponseMetadata></GetCallerIdentityResponse>`, account, account)
+			return
+		}
+
+		var input struct {
+			PaginationToken string
+			TagFilters      []struct {
+				Key    string
+				Values []string
+			}
+		}
+		_ = json.Unmarshal(body, &input)
+
+		region := "unknown"
+		if match := awsRequestRegion.FindStringSubmatch(r.Header.Get("Authorization")); match != nil {
+			region = match[1]
+		}
+
+		name, next := "bucket-a", "page-2"
+		if input.PaginationToken == "page-2" {
+			name, next = "bucket-b", ""
+		}
+		tags := []map[string]string{{"Key": "owner", "Value": "platform"}}
+		if len(input.TagFilters) > 0 && input.TagFilters[0].Values[0] != "platform" {
+			w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+			fmt.Fprint(w, `{"ResourceTagMappingList": []}`)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/x-amz-json-1.1")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"PaginationToken": next,
+			"ResourceTagMappingList": []map[string]interface{}{{
+				"ResourceARN": fmt.Sprintf("arn:aws:ec2:%s:%s:volume/%s", region, account, name),
+				"Tags":        tags,
+			}},
+		})
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestAWSRepository(t *testing.T, regions ...string) *AWSRepository {
+	t.Helper()
+
+	t.Setenv("AWS_CONFIG_FILE", "/dev/null")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", "/dev/null")
+
+	server := fakeAWS(t, "123456789012")
+	repository, err := NewAWSRepository(&entity.AWSProvider{
+		AccountID:       "123456789012",
+		AccessKeyID:     "AKID",
+		SecretAccessKey: "secret",
+		Region:          "us-east-1",
+		Regions:         regions,
+		Endpoint:        server.URL,
+	}, testTracer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repository.(*AWSRepository)
+}
+
+func awsResourceNames(resources []*entity.AWSResource) []string {
+	result := make([]string, 0, len(resources))
+	for _, resource := range resources {
+		result = append(result, fmt.Sprintf("%s/%s", resource.Region, resource.Name))
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestAWSRepositoryListResources(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		regions []string
+		want    []string
+	}{
+		{name: "default region", want: []string{"us-east-1/bucket-a", "us-east-1/bucket-b"}},
+		{
+			name:    "configured regions",
+			regions: []string{"us-east-1", "sa-east-1"},
+			want:    []string{"sa-east-1/bucket-a", "sa-east-1/bucket-b", "us-east-1/bucket-a", "us-east-1/bucket-b"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := newTestAWSRepository(t, tt.regions...).ListResources(context.Background())
+			if err != nil {
+				t.Fatalf("ListResources() error = %v", err)
+			}
+			if got := awsResourceNames(result); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ListResources() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSRepositoryListResourcesByRegion(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		region  string
+		want    []string
+		wantErr error
+	}{
+		{name: "configured region", region: "sa-east-1", want: []string{"sa-east-1/bucket-a", "sa-east-1/bucket-b"}},
+		{name: "unknown region", region: "mars-1", wantErr: entity.ErrRegionNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repository := newTestAWSRepository(t, "us-east-1", "sa-east-1")
+
+			result, err := repository.ListResourcesByRegion(context.Background(), tt.region)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("ListResourcesByRegion() error = %v, want %v", err, tt.wantErr)
+				}
+			} else if got := awsResourceNames(result); err != nil || strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ListResourcesByRegion() = %v, %v, want %v", got, err, tt.want)
+			}
+
+			// consultas não criam clientes fora da configuração
+			if len(repository.Clients) != 2 {
+				t.Errorf("Clients has %d regions, want 2", len(repository.Clients))
+			}
+		})
+	}
+}
+
+func TestAWSRepositoryListResourcesByTag(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		value string
+		want  int
+	}{
+		{name: "matching value", value: "platform", want: 2},
+		{name: "other value", value: "data", want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := newTestAWSRepository(t).ListResourcesByTag(context.Background(), "owner", tt.value)
+			if err != nil || len(result) != tt.want {
+				t.Errorf("ListResourcesByTag() = %d resources, %v, want %d", len(result), err, tt.want)
+			}
+		})
+	}
+}
+
+func TestAWSRepositoryGetAccount(t *testing.T) {
+
+	tests := []struct {
+		name    string
+		id      string
+		wantErr error
+	}{
+		{name: "any account", id: ""},
+		{name: "credential account", id: "123456789012"},
+		{name: "other account", id: "999999999999", wantErr: entity.ErrAccountNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			account, err := newTestAWSRepository(t).GetAccount(context.Background(), tt.id)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("GetAccount() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || account.ID != "123456789012" {
+				t.Errorf("GetAccount() = %+v, %v", account, err)
+			}
+		})
+	}
+}
+
+func TestParseAWSResource(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		arn      string
+		wantType string
+		wantName string
+		wantErr  bool
+	}{
+		{name: "type and name with slash", arn: "arn:aws:ec2:us-east-1:123456789012:volume/vol-1", wantType: "volume", wantName: "vol-1"},
+		{name: "type and name with colon", arn: "arn:aws:rds:us-east-1:123456789012:db:orders", wantType: "db", wantName: "orders"},
+		{name: "name only", arn: "arn:aws:s3:::bucket-a", wantName: "bucket-a"},
+		{name: "invalid arn", arn: "bucket-a", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resource, err := parseAWSResource(types.ResourceTagMapping{
+				ResourceARN: aws.String(tt.arn),
+				Tags:        []types.Tag{{Key: aws.String("owner"), Value: aws.String("platform")}},
+			})
+			if tt.wantErr {
+				if err == nil {
+					t.Error("parseAWSResource() returned no error")
+				}
+				return
+			}
+			if err != nil || resource.Type != tt.wantType || resource.Name != tt.wantName || resource.Tags["owner"] != "platform" {
+				t.Errorf("parseAWSResource() = %+v, %v, want type %q and name %q", resource, err, tt.wantType, tt.wantName)
+			}
+		})
+	}
+}
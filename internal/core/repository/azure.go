@@ -3,12 +3,29 @@ package repository
 import (
 	"context"
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"sort"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/cloud"
 	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
 	"github.com/Azure/azure-sdk-for-go/sdk/azidentity"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/authorization/armauthorization/v2"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/managementgroups/armmanagementgroups"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/monitor/armmonitor"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
@@ -16,18 +33,55 @@ import (
 )
 
 type AzureRepository struct {
-	Provider     entity.AzureProvider
-	Tracer       *otelpkg.OtelPkgInstrument
-	Client       *armresources.Client
-	Subscription entity.AzureSubscription
-	Credential   *azidentity.ClientSecretCredential
+	Provider       entity.AzureProvider
+	Tracer         *otelpkg.OtelPkgInstrument
+	Clients        map[string]*armresources.Client
+	ResourceGroups map[string]*armresources.ResourceGroupsClient
+	Graph          *armresourcegraph.Client
+	Providers      map[string]*armresources.ProvidersClient
+	Generic        *arm.Client
+	Entities       *armmanagementgroups.EntitiesClient
+	Roles          map[string]*armauthorization.RoleAssignmentsClient
+	ActivityLogs   map[string]*armmonitor.ActivityLogsClient
+	Subscriptions  []*armsubscriptions.Subscription
+	Credential     azcore.TokenCredential
+	Transport      policy.Transporter
+	Throttled      policy.Transporter
+	Cloud          cloud.Configuration
+
+	apiVersionsMu sync.Mutex
+	apiVersions   map[string]string
+}
+
+// AzureRepositoryOption
+// Opções aplicadas ao AzureRepository antes da conexão
+type AzureRepositoryOption func(*AzureRepository)
+
+// WithAzureTransport
+// Substitui o transporte HTTP da credencial e dos clients. Permite apontar para um servidor fake
+func WithAzureTransport(transport policy.Transporter) AzureRepositoryOption {
+	return func(a *AzureRepository) {
+		a.Transport = transport
+	}
 }
 
-func NewAzureRepository(provider *entity.AzureProvider, otl *otelpkg.OtelPkgInstrument) (entity.AzureProviderInterface, error) {
+// NewAzureRepository
+// Retorna o backend configurado em provider.Backend: arm (padrão) ou resourcegraph
+func NewAzureRepository(provider *entity.AzureProvider, otl *otelpkg.OtelPkgInstrument, opts ...AzureRepositoryOption) (entity.AzureProviderInterface, error) {
 
 	p := &AzureRepository{
-		Provider: *provider,
-		Tracer:   otl,
+		Provider:       *provider,
+		Tracer:         otl,
+		Clients:        make(map[string]*armresources.Client),
+		ResourceGroups: make(map[string]*armresources.ResourceGroupsClient),
+		Providers:      make(map[string]*armresources.ProvidersClient),
+		Roles:          make(map[string]*armauthorization.RoleAssignmentsClient),
+		ActivityLogs:   make(map[string]*armmonitor.ActivityLogsClient),
+		apiVersions:    make(map[string]string),
+	}
+
+	for _, opt := range opts {
+		opt(p)
 	}
 
 	ctxSpan, span := p.Tracer.Tracer.Start(context.Background(), "AzureRepository.NewAzureRepository")
@@ -36,6 +90,10 @@ func NewAzureRepository(provider *entity.AzureProvider, otl *otelpkg.OtelPkgInst
 	if err := p.Connection(ctxSpan, &p.Provider); err != nil {
 		return nil, err
 	}
+
+	if p.Provider.Backend == entity.AzureBackendResourceGraph {
+		return &AzureResourceGraphRepository{AzureRepository: p}, nil
+	}
 	return p, nil
 }
 
@@ -46,154 +104,820 @@ func (a *AzureRepository) Connection(ctx context.Context, provider *entity.Azure
 
 	var err error
 
-	transport := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+	if a.Transport == nil {
+		a.Transport, err = newAzureTransport(provider)
+		if err != nil {
+			return fmt.Errorf("failed to create http transport: %w", err)
+		}
 	}
 
-	// Create a custom HTTP client with the custom transport
-	httpClient := &http.Client{
-		Transport: transport,
+	a.Throttled = newAzureThrottledTransport(a.Transport, provider.GetName(), provider.Throttling)
+	a.Cloud = azureCloudConfiguration(provider)
+
+	a.Credential, err = a.newCredential(provider)
+	if err != nil {
+		return fmt.Errorf("failed to create %s credential: %w", provider.GetAuthType(), err)
+	}
+
+	a.Subscriptions, err = a.discoverSubscriptions(ctxSpan)
+	if err != nil {
+		return fmt.Errorf("failed to discover subscriptions: %w", err)
 	}
 
-	// Set up the Azure identity client options to use the custom HTTP client
-	credOptions := &azidentity.ClientSecretCredentialOptions{
+	for _, subscription := range a.Subscriptions {
+		id := *subscription.SubscriptionID
+
+		client, err := armresources.NewClient(id, a.Credential, a.clientOptions())
+		if err != nil {
+			return fmt.Errorf("failed to create client connection: %w", err)
+		}
+		rsgClient, err := armresources.NewResourceGroupsClient(id, a.Credential, a.clientOptions())
+		if err != nil {
+			return fmt.Errorf("failed to create resource group client connection: %w", err)
+		}
+
+		providersClient, err := armresources.NewProvidersClient(id, a.Credential, a.clientOptions())
+		if err != nil {
+			return fmt.Errorf("failed to create providers client connection: %w", err)
+		}
+
+		rolesClient, err := armauthorization.NewRoleAssignmentsClient(id, a.Credential, a.clientOptions())
+		if err != nil {
+			return fmt.Errorf("failed to create role assignments client connection: %w", err)
+		}
+
+		activityLogsClient, err := armmonitor.NewActivityLogsClient(id, a.Credential, a.clientOptions())
+		if err != nil {
+			return fmt.Errorf("failed to create activity logs client connection: %w", err)
+		}
+
+		a.Clients[id] = client
+		a.ResourceGroups[id] = rsgClient
+		a.Providers[id] = providersClient
+		a.Roles[id] = rolesClient
+		a.ActivityLogs[id] = activityLogsClient
+	}
+
+	a.Graph, err = armresourcegraph.NewClient(a.Credential, a.clientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create resource graph client connection: %w", err)
+	}
+
+	a.Generic, err = arm.NewClient("golang-cloud-collector", "v1.0.0", a.Credential, a.clientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create arm client connection: %w", err)
+	}
+
+	a.Entities, err = armmanagementgroups.NewEntitiesClient(a.Credential, a.clientOptions())
+	if err != nil {
+		return fmt.Errorf("failed to create management groups client connection: %w", err)
+	}
+
+	return nil
+}
+
+// clientOptions
+// Opções comuns dos clients ARM. Todos compartilham o transporte com orçamento de requisições,
+// e o retry do azcore deixa de tratar 429/503 para não multiplicar as tentativas
+func (a *AzureRepository) clientOptions() *arm.ClientOptions {
+	return &arm.ClientOptions{
 		ClientOptions: policy.ClientOptions{
-			Transport: httpClient,
+			Cloud:     a.Cloud,
+			Transport: a.Throttled,
+			Retry: policy.RetryOptions{
+				StatusCodes: azureRetryStatusCodes,
+			},
 		},
 	}
+}
+
+// azureCloudConfiguration
+// Authority host e endpoint do Resource Manager de cada nuvem
+func azureCloudConfiguration(provider *entity.AzureProvider) cloud.Configuration {
+
+	switch provider.GetCloud() {
+	case entity.AzureCloudChina:
+		return cloud.AzureChina
+	case entity.AzureCloudUSGovernment:
+		return cloud.AzureGovernment
+	case entity.AzureCloudCustom:
+		audience := provider.CloudEndpoints.ResourceManagerAudience
+		if audience == "" {
+			audience = provider.CloudEndpoints.ResourceManagerEndpoint
+		}
+		return cloud.Configuration{
+			ActiveDirectoryAuthorityHost: provider.CloudEndpoints.AuthorityHost,
+			Services: map[cloud.ServiceName]cloud.ServiceConfiguration{
+				cloud.ResourceManager: {
+					Audience: audience,
+					Endpoint: provider.CloudEndpoints.ResourceManagerEndpoint,
+				},
+			},
+		}
+	}
+
+	return cloud.AzurePublic
+}
+
+// newAzureTransport
+// Transporte HTTP compartilhado pela credencial e pelos clients.
+// Verifica o TLS com os certificados do sistema mais o bundle de provider.TLS.CAFile
+// e usa provider.Proxy ou as variáveis HTTPS_PROXY/NO_PROXY do ambiente
+func newAzureTransport(provider *entity.AzureProvider) (*http.Client, error) {
+
+	rootCAs, err := x509.SystemCertPool()
+	if err != nil || rootCAs == nil {
+		rootCAs = x509.NewCertPool()
+	}
+
+	if provider.TLS.CAFile != "" {
+		data, err := os.ReadFile(provider.TLS.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		if !rootCAs.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificate found in %s", provider.TLS.CAFile)
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		RootCAs:            rootCAs,
+		InsecureSkipVerify: provider.TLS.InsecureSkipVerify,
+	}
+
+	transport.Proxy = http.ProxyFromEnvironment
+	if provider.Proxy != "" {
+		proxy, err := url.Parse(provider.Proxy)
+		if err != nil {
+			return nil, err
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+
+	return &http.Client{
+		Transport: transport,
+	}, nil
+}
+
+// newCredential
+// Cria a credencial de acordo com provider.AuthType usando o transporte do repositório
+func (a *AzureRepository) newCredential(provider *entity.AzureProvider) (azcore.TokenCredential, error) {
+
+	clientOptions := policy.ClientOptions{
+		Cloud:     a.Cloud,
+		Transport: a.Transport,
+	}
+
+	switch provider.GetAuthType() {
+	case entity.AzureAuthClientCertificate:
+		data, err := os.ReadFile(provider.CertificatePath)
+		if err != nil {
+			return nil, err
+		}
+
+		certs, key, err := azidentity.ParseCertificates(data, []byte(provider.CertificatePassword))
+		if err != nil {
+			return nil, err
+		}
+
+		return azidentity.NewClientCertificateCredential(provider.Tenant, provider.ApplicationID, certs, key, &azidentity.ClientCertificateCredentialOptions{
+			ClientOptions: clientOptions,
+		})
+	case entity.AzureAuthWorkloadIdentity:
+		return azidentity.NewWorkloadIdentityCredential(&azidentity.WorkloadIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+			ClientID:      provider.ApplicationID,
+			TenantID:      provider.Tenant,
+			TokenFilePath: provider.TokenFile,
+		})
+	case entity.AzureAuthManagedIdentity:
+		options := &azidentity.ManagedIdentityCredentialOptions{
+			ClientOptions: clientOptions,
+		}
+		if provider.ApplicationID != "" {
+			options.ID = azidentity.ClientID(provider.ApplicationID)
+		}
+		return azidentity.NewManagedIdentityCredential(options)
+	case entity.AzureAuthCLI:
+		return azidentity.NewAzureCLICredential(&azidentity.AzureCLICredentialOptions{
+			TenantID: provider.Tenant,
+		})
+	case entity.AzureAuthDefault:
+		return azidentity.NewDefaultAzureCredential(&azidentity.DefaultAzureCredentialOptions{
+			ClientOptions: clientOptions,
+			TenantID:      provider.Tenant,
+		})
+	}
 
-	a.Credential, err = azidentity.NewClientSecretCredential(provider.Tenant, provider.ApplicationID, provider.ApplicationSecret, &azidentity.ClientSecretCredentialOptions{
-		ClientOptions: credOptions.ClientOptions,
+	return azidentity.NewClientSecretCredential(provider.Tenant, provider.ApplicationID, provider.ApplicationSecret, &azidentity.ClientSecretCredentialOptions{
+		ClientOptions: clientOptions,
 	})
+}
+
+// discoverSubscriptions
+// Sem all_subscriptions retorna somente a assinatura configurada.
+// Com all_subscriptions retorna as assinaturas habilitadas que passam pelas listas de inclusão e exclusão
+func (a *AzureRepository) discoverSubscriptions(ctx context.Context) ([]*armsubscriptions.Subscription, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.discoverSubscriptions")
+	defer span.End()
+
+	if !a.Provider.AllSubscriptions {
+		subscription, err := a.GetSubscription(ctxSpan, a.Provider.Subscription, "")
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if subscription == nil {
+			subscription = &armsubscriptions.Subscription{SubscriptionID: &a.Provider.Subscription}
+		}
+		return []*armsubscriptions.Subscription{subscription}, nil
+	}
+
+	sub, err := armsubscriptions.NewClient(a.Credential, a.clientOptions())
 	if err != nil {
-		return fmt.Errorf("failed to create client secret credential: %w", err)
+		return nil, err
 	}
 
-	a.GetSubscription(ctxSpan, a.Provider.Subscription, "")
+	var result []*armsubscriptions.Subscription
+	pager := sub.NewListPager(nil)
+	for pager.More() {
+		page, err := pager.NextPage(ctxSpan)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
 
-	client, err := armresources.NewClient(a.Provider.Subscription, a.Credential, nil)
-	if err != nil {
-		return fmt.Errorf("failed to create client connection: %w", err)
+		for _, subscription := range page.SubscriptionListResult.Value {
+			if subscription.SubscriptionID == nil || subscription.State == nil || *subscription.State != armsubscriptions.SubscriptionStateEnabled {
+				continue
+			}
+
+			name := ""
+			if subscription.DisplayName != nil {
+				name = *subscription.DisplayName
+			}
+			if a.Provider.AllowSubscription(*subscription.SubscriptionID, name) {
+				result = append(result, subscription)
+			}
+		}
+	}
+
+	if len(result) == 0 {
+		return nil, errors.New("no enabled subscription is visible to the credential")
+	}
+
+	return result, nil
+}
+
+func (a *AzureRepository) ListSubscriptions(ctx context.Context) ([]*armsubscriptions.Subscription, error) {
+	_, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListSubscriptions")
+	defer span.End()
+
+	return a.Subscriptions, nil
+}
+
+// subscriptionIDs
+// IDs das assinaturas coletadas em ordem determinística
+func (a *AzureRepository) subscriptionIDs() []string {
+	ids := make([]string, 0, len(a.Clients))
+	for id := range a.Clients {
+		ids = append(ids, id)
 	}
-	// token, err := getAccessToken(cred)
+	sort.Strings(ids)
+	return ids
+}
+
+// resourceExpand campos adicionais solicitados ao ARM na listagem de recursos
+const resourceExpand = "createdTime,changedTime,provisioningState"
+
+// PageResources
+// Percorre as páginas de todas as assinaturas e entrega cada página no canal.
+// O canal é fechado ao final, no primeiro erro ou quando o contexto é cancelado.
+// O $filter do ARM não aceita createdTime ou changedTime, então filter.Since é aplicado em cada página
+func (a *AzureRepository) PageResources(ctx context.Context, filter *entity.AzureResourceFilter) <-chan entity.AzureResourcePage {
+
+	pages := make(chan entity.AzureResourcePage)
+	go func() {
+		defer close(pages)
+
+		ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.PageResources")
+		defer span.End()
+
+		for _, id := range a.subscriptionIDs() {
+			if !filter.AllowSubscription(id) {
+				continue
+			}
+
+			var err error
+			if filter.ResourceGroup != "" {
+				pager := a.Clients[id].NewListByResourceGroupPager(filter.ResourceGroup, &armresources.ClientListByResourceGroupOptions{
+					Filter: tagFilter(filter),
+					Expand: to.Ptr(resourceExpand),
+				})
+				err = streamPager(ctxSpan, pager, pages, func(resp armresources.ClientListByResourceGroupResponse) []*armresources.GenericResourceExpanded {
+					return changedSince(filter, resp.Value)
+				})
+				if isNotFound(err) {
+					// the resource group does not exist in this subscription
+					continue
+				}
+			} else {
+				pager := a.Clients[id].NewListPager(&armresources.ClientListOptions{
+					Filter: tagFilter(filter),
+					Expand: to.Ptr(resourceExpand),
+				})
+				err = streamPager(ctxSpan, pager, pages, func(resp armresources.ClientListResponse) []*armresources.GenericResourceExpanded {
+					return changedSince(filter, resp.Value)
+				})
+			}
 
-	a.Client = client
+			if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return
+			}
+			if err != nil {
+				span.RecordError(err)
+				sendPage(ctxSpan, pages, entity.AzureResourcePage{Err: fmt.Errorf("failed to list resources: %w", err)})
+				return
+			}
+		}
+	}()
 
+	return pages
+}
+
+// streamPager
+// Envia cada página do pager no canal até o fim, um erro ou o cancelamento do contexto
+func streamPager[T any](ctx context.Context, pager *runtime.Pager[T], pages chan<- entity.AzureResourcePage, value func(T) []*armresources.GenericResourceExpanded) error {
+	for pager.More() {
+		resp, err := pager.NextPage(ctx)
+		if err != nil {
+			return err
+		}
+		if !sendPage(ctx, pages, entity.AzureResourcePage{Resources: value(resp)}) {
+			return ctx.Err()
+		}
+	}
 	return nil
 }
 
+// sendPage
+// Retorna false quando o contexto é cancelado antes do consumidor receber a página
+func sendPage(ctx context.Context, pages chan<- entity.AzureResourcePage, page entity.AzureResourcePage) bool {
+	select {
+	case pages <- page:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// collectPages
+// Acumula todas as páginas do iterador
+func collectPages(ctx context.Context, pages <-chan entity.AzureResourcePage) ([]*armresources.GenericResourceExpanded, error) {
+	var result []*armresources.GenericResourceExpanded = nil
+	for page := range pages {
+		if page.Err != nil {
+			return nil, page.Err
+		}
+		result = append(result, page.Resources...)
+	}
+	return result, ctx.Err()
+}
+
+// changedSince
+// Recursos da página criados ou alterados a partir de filter.Since
+func changedSince(filter *entity.AzureResourceFilter, resources []*armresources.GenericResourceExpanded) []*armresources.GenericResourceExpanded {
+	if filter.Since.IsZero() {
+		return resources
+	}
+
+	result := make([]*armresources.GenericResourceExpanded, 0, len(resources))
+	for _, r := range resources {
+		if filter.AllowTime(r.CreatedTime, r.ChangedTime) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+func tagFilter(filter *entity.AzureResourceFilter) *string {
+	if filter.TagKey == "" {
+		return nil
+	}
+	v := fmt.Sprintf("tagName eq '%s' and tagValue eq '%s'", filter.TagKey, filter.TagValue)
+	return &v
+}
+
 func (a *AzureRepository) ListResources(ctx context.Context) ([]*armresources.GenericResourceExpanded, error) {
 	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListResources")
 	defer span.End()
 
-	pager := a.Client.NewListPager(&armresources.ClientListOptions{})
+	return collectPages(ctxSpan, a.PageResources(ctxSpan, &entity.AzureResourceFilter{}))
+}
 
-	var result []*armresources.GenericResourceExpanded = nil
+func (a *AzureRepository) ListResourcesByTag(ctx context.Context, tagKey, tagValue string) ([]*armresources.GenericResourceExpanded, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListResourcesByTag")
+	defer span.End()
+
+	return collectPages(ctxSpan, a.PageResources(ctxSpan, &entity.AzureResourceFilter{
+		TagKey:   tagKey,
+		TagValue: tagValue,
+	}))
+}
+
+func (a *AzureRepository) ListResourcesByResourceGroup(ctx context.Context, rsg string) ([]*armresources.GenericResourceExpanded, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListResourcesByResourceGroup")
+	defer span.End()
+
+	return collectPages(ctxSpan, a.PageResources(ctxSpan, &entity.AzureResourceFilter{
+		ResourceGroup: rsg,
+	}))
+}
+
+// GetResourceByID
+// Busca as propriedades completas do recurso com a api-version do seu tipo
+func (a *AzureRepository) GetResourceByID(ctx context.Context, resourceID string) (*entity.AzureResourceDetail, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.GetResourceByID")
+	defer span.End()
+
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	client, ok := a.Clients[id.SubscriptionID]
+	if !ok {
+		err := fmt.Errorf("subscription %s: %w", id.SubscriptionID, entity.ErrSubscriptionNotCollected)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	resourceType := strings.Join(id.ResourceType.Types, "/")
+	apiVersion, err := a.resolveAPIVersion(ctxSpan, id.SubscriptionID, id.ResourceType.Namespace, resourceType)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	resp, err := client.GetByID(ctxSpan, id.String(), apiVersion, nil)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get resource: %w", err)
+	}
+
+	return &entity.AzureResourceDetail{
+		ID:             id.String(),
+		Name:           id.Name,
+		SubscriptionID: id.SubscriptionID,
+		ResourceGroup:  id.ResourceGroupName,
+		Provider:       id.ResourceType.Namespace,
+		Type:           resourceType,
+		APIVersion:     apiVersion,
+		Resource:       &resp.GenericResource,
+	}, nil
+}
+
+// ListManagementGroups
+// Lê a árvore de grupos de gerenciamento visível pela credencial pela API de entidades,
+// que retorna grupos e assinaturas com o respectivo pai em uma única listagem
+func (a *AzureRepository) ListManagementGroups(ctx context.Context) ([]*entity.AzureManagementGroup, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListManagementGroups")
+	defer span.End()
+
+	groups := make(map[string]*entity.AzureManagementGroup)
+	subscriptions := make(map[string]string)
+
+	pager := a.Entities.NewListPager(nil)
 	for pager.More() {
-		resp, err := pager.NextPage(ctxSpan)
+		page, err := pager.NextPage(ctxSpan)
 		if err != nil {
 			span.RecordError(err)
-			return nil, fmt.Errorf("failed to list resources: %w", err)
+			return nil, fmt.Errorf("failed to list management groups: %w", err)
 		}
-		result = resp.Value
+
+		for _, e := range page.Value {
+			if e.ID == nil || e.Type == nil {
+				continue
+			}
+
+			parentID := ""
+			if e.Properties != nil && e.Properties.Parent != nil && e.Properties.Parent.ID != nil {
+				parentID = *e.Properties.Parent.ID
+			}
+
+			if strings.EqualFold(*e.Type, "/subscriptions") {
+				subscriptions[azureName(e.Name)] = strings.ToLower(parentID)
+				continue
+			}
+
+			group := &entity.AzureManagementGroup{
+				ID:       *e.ID,
+				Name:     azureName(e.Name),
+				ParentID: parentID,
+			}
+			if e.Properties != nil {
+				group.DisplayName = azureName(e.Properties.DisplayName)
+				group.TenantID = azureName(e.Properties.TenantID)
+			}
+			groups[strings.ToLower(group.ID)] = group
+		}
+	}
+
+	for subscription, parentID := range subscriptions {
+		if group, ok := groups[parentID]; ok {
+			group.Subscriptions = append(group.Subscriptions, subscription)
+		}
+	}
+
+	result := make([]*entity.AzureManagementGroup, 0, len(groups))
+	for _, group := range groups {
+		sort.Strings(group.Subscriptions)
+		result = append(result, group)
 	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID < result[j].ID })
 
 	return result, nil
 }
 
-func (a *AzureRepository) ListResourcesByTag(ctx context.Context, tagKey, tagValue string) ([]*armresources.GenericResourceExpanded, error) {
-	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListResourcesByTag")
+// ListRoleAssignments
+// Todas as atribuições de função da assinatura, incluindo as de grupos de recursos e recursos,
+// em uma única listagem. O chamador agrupa pelo Scope
+func (a *AzureRepository) ListRoleAssignments(ctx context.Context, subscriptionID string) ([]*entity.AzureRoleAssignment, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListRoleAssignments")
 	defer span.End()
 
-	filter := fmt.Sprintf("tagName eq '%s' and tagValue eq '%s'", tagKey, tagValue)
-
-	pager := a.Client.NewListPager(&armresources.ClientListOptions{
-		Filter: &filter,
-	})
+	client, ok := a.Roles[subscriptionID]
+	if !ok {
+		err := fmt.Errorf("subscription %s: %w", subscriptionID, entity.ErrSubscriptionNotCollected)
+		span.RecordError(err)
+		return nil, err
+	}
 
-	var result []*armresources.GenericResourceExpanded = nil
+	var result []*entity.AzureRoleAssignment
+	pager := client.NewListForSubscriptionPager(nil)
 	for pager.More() {
-		resp, err := pager.NextPage(ctxSpan)
+		page, err := pager.NextPage(ctxSpan)
 		if err != nil {
 			span.RecordError(err)
-			return nil, fmt.Errorf("failed to list resources: %w", err)
+			return nil, fmt.Errorf("failed to list role assignments: %w", err)
+		}
+
+		for _, r := range page.Value {
+			if r.Properties == nil {
+				continue
+			}
+
+			assignment := &entity.AzureRoleAssignment{
+				ID:               azureName(r.ID),
+				Scope:            azureName(r.Properties.Scope),
+				PrincipalID:      azureName(r.Properties.PrincipalID),
+				RoleDefinitionID: strings.ToLower(path.Base(azureName(r.Properties.RoleDefinitionID))),
+			}
+			if r.Properties.PrincipalType != nil {
+				assignment.PrincipalType = string(*r.Properties.PrincipalType)
+			}
+			result = append(result, assignment)
 		}
-		result = resp.Value
 	}
 
 	return result, nil
 }
 
-func (a *AzureRepository) ListResourcesByResourceGroup(ctx context.Context, rsg string) ([]*armresources.GenericResourceExpanded, error) {
-	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListResourcesByResourceGroup")
+// activityLogSelect campos lidos do Activity Log
+const activityLogSelect = "caller,claims,eventTimestamp,operationName,resourceId,status"
+
+// ListActivityLog
+// Eventos administrativos da assinatura a partir de since
+func (a *AzureRepository) ListActivityLog(ctx context.Context, subscriptionID string, since time.Time) ([]*entity.AzureActivityEvent, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListActivityLog")
 	defer span.End()
 
-	pager := a.Client.NewListByResourceGroupPager(rsg, &armresources.ClientListByResourceGroupOptions{})
+	client, ok := a.ActivityLogs[subscriptionID]
+	if !ok {
+		err := fmt.Errorf("subscription %s is not collected by this account", subscriptionID)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	filter := fmt.Sprintf("eventTimestamp ge '%s' and eventTimestamp le '%s' and category eq 'Administrative'",
+		since.UTC().Format(time.RFC3339), time.Now().UTC().Format(time.RFC3339))
 
-	var result []*armresources.GenericResourceExpanded = nil
+	var result []*entity.AzureActivityEvent
+	pager := client.NewListPager(filter, &armmonitor.ActivityLogsClientListOptions{Select: to.Ptr(activityLogSelect)})
 	for pager.More() {
-		resp, err := pager.NextPage(ctxSpan)
+		page, err := pager.NextPage(ctxSpan)
 		if err != nil {
 			span.RecordError(err)
-			return nil, fmt.Errorf("failed to list resources: %w", err)
+			return nil, fmt.Errorf("failed to list activity log: %w", err)
+		}
+
+		for _, e := range page.Value {
+			if e.ResourceID == nil || e.OperationName == nil || e.EventTimestamp == nil {
+				continue
+			}
+
+			event := &entity.AzureActivityEvent{
+				ResourceID:     *e.ResourceID,
+				OperationName:  azureName(e.OperationName.Value),
+				Caller:         azureName(e.Caller),
+				PrincipalID:    azureName(e.Claims["http://schemas.microsoft.com/identity/claims/objectidentifier"]),
+				EventTimestamp: *e.EventTimestamp,
+			}
+			if e.Status != nil {
+				event.Status = azureName(e.Status.Value)
+			}
+			if event.Caller == "" {
+				event.Caller = azureName(e.Claims["http://schemas.xmlsoap.org/ws/2005/05/identity/claims/upn"])
+			}
+			result = append(result, event)
 		}
-		result = resp.Value
 	}
 
 	return result, nil
 }
 
-// func (a *AzureRepository) ListResourcesByID(ctx context.Context, rsg string) ([]*armresources.GenericResourceExpanded, error) {
-
-// 	pager := a.Client.GetByID(context.Background(), rsg)
-
-// 	var result []*armresources.GenericResourceExpanded = nil
-// 	for pager.More() {
-// 		resp, err := pager.NextPage(ctx)
-// 		if err != nil {
-// 			return nil, fmt.Errorf("failed to list resources: %w", err)
-// 		}
-// 		result = resp.Value
-// 	}
-
-// 	return result, nil
-// }
-
-func getAccessToken(cred *azidentity.ClientSecretCredential) (string, error) {
-	// const tenantInfoURL string = "https://graph.microsoft.com/v1.0/organization"
-	// // Faz a requisição para a Microsoft Graph API
-	// req, err := http.NewRequest("GET", tenantInfoURL, nil)
-	// if err != nil {
-	// 	log.Fatalf("falha ao criar requisição: %v", err)
-	// }
-
-	// req.Header.Add("Authorization", "Bearer "+token)
-	// resp, err := http.DefaultClient.Do(req)
-	// if err != nil {
-	// 	log.Fatalf("falha ao fazer a requisição: %v", err)
-	// }
-	// defer resp.Body.Close()
-
-	// body, err := ioutil.ReadAll(resp.Body)
-	// if err != nil {
-	// 	log.Fatalf("falha ao ler a resposta: %v", err)
-	// }
-	// var result map[string]interface{}
-	// if err := json.Unmarshal(body, &result); err != nil {
-	// 	log.Fatalf("falha ao parsear JSON: %v", err)
-	// }
-
-	// Imprime o nome do tenant
-	// organizations := result["value"].([]interface{})
-	// for _, org := range organizations {
-	// 	orgDetails := org.(map[string]interface{})
-	// }
-	// if err != nil {	// 	return err
-	// }
-
-	ctx := context.Background()
+func azureName(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
+}
+
+// ListChildResources
+// Lista os filhos de parentID em {parentID}/{path}, seguindo o nextLink
+func (a *AzureRepository) ListChildResources(ctx context.Context, parentID string, path string) ([]*armresources.GenericResourceExpanded, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListChildResources")
+	defer span.End()
+
+	parent, err := arm.ParseResourceID(parentID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	child := entity.AzureChildResource{
+		ParentType: parent.ResourceType.String(),
+		Path:       path,
+	}
+	childType := strings.TrimPrefix(child.ChildType(), parent.ResourceType.Namespace+"/")
+
+	apiVersion, err := a.resolveAPIVersion(ctxSpan, parent.SubscriptionID, parent.ResourceType.Namespace, childType)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	var result []*armresources.GenericResourceExpanded
+	next := runtime.JoinPaths(a.Generic.Endpoint(), parent.String(), strings.Trim(path, "/")) + "?api-version=" + apiVersion
+	for next != "" {
+		page, err := a.childResourcesPage(ctxSpan, next)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if page == nil {
+			return result, nil
+		}
+
+		for _, v := range page.Value {
+			if v.Type == nil {
+				v.Type = to.Ptr(child.ChildType())
+			}
+		}
+		result = append(result, page.Value...)
+
+		next = ""
+		if page.NextLink != nil {
+			next = *page.NextLink
+		}
+	}
+
+	return result, nil
+}
+
+// childResourcesPage
+// Uma página da listagem de recursos filhos. Retorna nil quando o pai não tem a coleção (404).
+// O corpo da resposta é fechado em todos os caminhos
+func (a *AzureRepository) childResourcesPage(ctx context.Context, link string) (*childResourcesPage, error) {
+
+	req, err := runtime.NewRequest(ctx, http.MethodGet, link)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := a.Generic.Pipeline().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		return nil, fmt.Errorf("failed to list child resources: %w", runtime.NewResponseError(resp))
+	}
+
+	var page childResourcesPage
+	if err := runtime.UnmarshalAsJSON(resp, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+type childResourcesPage struct {
+	Value    []*armresources.GenericResourceExpanded `json:"value"`
+	NextLink *string                                 `json:"nextLink"`
+}
+
+// resolveAPIVersion
+// Consulta a API de Providers e guarda em memória a versão estável mais recente de cada tipo
+func (a *AzureRepository) resolveAPIVersion(ctx context.Context, subscriptionID, namespace, resourceType string) (string, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.resolveAPIVersion")
+	defer span.End()
+
+	key := strings.ToLower(fmt.Sprintf("%s/%s", namespace, resourceType))
+
+	a.apiVersionsMu.Lock()
+	version, ok := a.apiVersions[key]
+	a.apiVersionsMu.Unlock()
+	if ok {
+		return version, nil
+	}
+
+	providers, ok := a.Providers[subscriptionID]
+	if !ok {
+		err := fmt.Errorf("subscription %s is not collected by this account", subscriptionID)
+		span.RecordError(err)
+		return "", err
+	}
+
+	resp, err := providers.Get(ctxSpan, namespace, nil)
+	if err != nil {
+		span.RecordError(err)
+		return "", fmt.Errorf("failed to get provider %s: %w", namespace, err)
+	}
+
+	for _, rt := range resp.ResourceTypes {
+		if rt.ResourceType == nil || !strings.EqualFold(*rt.ResourceType, resourceType) {
+			continue
+		}
+
+		version = latestAPIVersion(rt.APIVersions)
+		if version == "" {
+			break
+		}
+
+		a.apiVersionsMu.Lock()
+		a.apiVersions[key] = version
+		a.apiVersionsMu.Unlock()
+		return version, nil
+	}
+
+	return "", fmt.Errorf("no api-version found for %s", key)
+}
+
+// latestAPIVersion
+// Versões são datas no formato AAAA-MM-DD[-preview]. Prefere a mais recente sem sufixo de preview
+func latestAPIVersion(versions []*string) string {
+
+	var stable, preview []string
+	for _, v := range versions {
+		if v == nil {
+			continue
+		}
+		if strings.Contains(strings.ToLower(*v), "preview") {
+			preview = append(preview, *v)
+			continue
+		}
+		stable = append(stable, *v)
+	}
+
+	for _, list := range [][]string{stable, preview} {
+		if len(list) > 0 {
+			sort.Sort(sort.Reverse(sort.StringSlice(list)))
+			return list[0]
+		}
+	}
+	return ""
+}
+
+func isNotFound(err error) bool {
+	var respErr *azcore.ResponseError
+	return errors.As(err, &respErr) && respErr.StatusCode == http.StatusNotFound
+}
+
+// getAccessToken
+// Token da credencial para APIs fora do Resource Manager, como o Microsoft Graph
+func getAccessToken(ctx context.Context, cred azcore.TokenCredential, scope string) (string, error) {
 	token, err := cred.GetToken(ctx, policy.TokenRequestOptions{
-		Scopes: []string{"https://graph.microsoft.com/.default"},
+		Scopes: []string{scope},
 	})
 	if err != nil {
 		return "", err
@@ -205,17 +929,27 @@ func (a *AzureRepository) GetSubscription(ctx context.Context, name string, id s
 	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.GetSubscription")
 	defer span.End()
 
-	sub, err := armsubscriptions.NewClient(a.Credential, nil)
+	if id == "" {
+		id = a.Provider.Subscription
+	}
+
+	// assinaturas já descobertas na conexão não geram uma nova chamada ao ARM
+	for _, subscription := range a.Subscriptions {
+		if subscription.DisplayName != nil && strings.EqualFold(*subscription.DisplayName, name) {
+			return subscription, nil
+		}
+		if subscription.SubscriptionID != nil && *subscription.SubscriptionID == id {
+			return subscription, nil
+		}
+	}
+
+	sub, err := armsubscriptions.NewClient(a.Credential, a.clientOptions())
 	if err != nil {
 		return nil, err
 	}
 
 	pager := sub.NewListPager(nil)
 
-	if id == "" {
-		id = a.Provider.Subscription
-	}
-
 	for pager.More() {
 		page, err := pager.NextPage(ctxSpan)
 		if err != nil {
@@ -237,32 +971,57 @@ func (a *AzureRepository) GetSubscription(ctx context.Context, name string, id s
 	return nil, nil
 }
 
-func (a *AzureRepository) FilterResources(ctx context.Context, name ...string) ([]*armresources.ResourceGroup, error) {
-	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.FilterResources")
+// GetResourceGroup
+// Grupo de recursos da assinatura. Retorna nil quando o grupo não existe
+func (a *AzureRepository) GetResourceGroup(ctx context.Context, subscriptionID, name string) (*armresources.ResourceGroup, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.GetResourceGroup")
 	defer span.End()
 
-	resourceGroupsClient, _ := armresources.NewResourceGroupsClient(a.Provider.Subscription, a.Credential, nil)
+	client, ok := a.ResourceGroups[subscriptionID]
+	if !ok {
+		err := fmt.Errorf("subscription %s: %w", subscriptionID, entity.ErrSubscriptionNotCollected)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	resp, err := client.Get(ctxSpan, name, nil)
+	if isNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get resource group: %w", err)
+	}
+
+	return &resp.ResourceGroup, nil
+}
 
-	pager := resourceGroupsClient.NewListPager(&armresources.ResourceGroupsClientListOptions{})
+func (a *AzureRepository) FilterResources(ctx context.Context, name ...string) ([]*armresources.ResourceGroup, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.FilterResources")
+	defer span.End()
 
 	var rsg []*armresources.ResourceGroup
 	var err error
-	for pager.More() {
-		page, err := pager.NextPage(ctxSpan)
-		if err != nil {
-			span.RecordError(err)
-			return nil, err
-		}
+	for _, id := range a.subscriptionIDs() {
+		pager := a.ResourceGroups[id].NewListPager(&armresources.ResourceGroupsClientListOptions{})
+
+		for pager.More() {
+			page, err := pager.NextPage(ctxSpan)
+			if err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
 
-		if len(name) > 0 {
-			for _, rg := range page.Value {
-				if strings.Contains(*rg.Name, name[0]) {
-					rsg = append(rsg, rg)
+			if len(name) > 0 {
+				for _, rg := range page.Value {
+					if strings.Contains(*rg.Name, name[0]) {
+						rsg = append(rsg, rg)
+					}
 				}
+				continue
 			}
-			return rsg, err
+			rsg = append(rsg, page.Value...)
 		}
-		rsg = page.Value
 	}
 	return rsg, err
 }
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// graphGroupSelect campos do grupo lidos no Microsoft Graph
+const graphGroupSelect = "id,displayName,description,mail,mailNickname"
+
+// graphUserSelect campos dos membros lidos no Microsoft Graph
+const graphUserSelect = "id,displayName,userPrincipalName,mail"
+
+var graphObjectID = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+// graphObject
+// Campos comuns de grupos e usuários retornados pelo Graph
+type graphObject struct {
+	ODataType         string `json:"@odata.type"`
+	ID                string `json:"id"`
+	DisplayName       string `json:"displayName"`
+	Description       string `json:"description"`
+	Mail              string `json:"mail"`
+	MailNickname      string `json:"mailNickname"`
+	UserPrincipalName string `json:"userPrincipalName"`
+}
+
+type graphPage struct {
+	Value    []graphObject `json:"value"`
+	NextLink string        `json:"@odata.nextLink"`
+}
+
+// ListDirectoryGroups
+// Lê os grupos configurados em graph.groups e os usuários membros diretos de cada um
+func (a *AzureRepository) ListDirectoryGroups(ctx context.Context) ([]*entity.AzureDirectoryGroup, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListDirectoryGroups")
+	defer span.End()
+
+	if !a.Provider.Graph.Enabled() {
+		return nil, nil
+	}
+
+	cloud := a.Provider.GetCloud()
+	baseURL := a.Provider.Graph.GetBaseURL(cloud)
+
+	token, err := getAccessToken(ctxSpan, a.Credential, a.Provider.Graph.GetScope(cloud))
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get microsoft graph token: %w", err)
+	}
+
+	var result []*entity.AzureDirectoryGroup
+	for _, name := range a.Provider.Graph.Groups {
+		groups, err := a.findDirectoryGroups(ctxSpan, baseURL, token, name)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		for _, g := range groups {
+			group := &entity.AzureDirectoryGroup{
+				ID:           g.ID,
+				DisplayName:  g.DisplayName,
+				Description:  g.Description,
+				Mail:         g.Mail,
+				MailNickname: g.MailNickname,
+			}
+
+			members, err := a.graphList(ctxSpan, token, fmt.Sprintf("%s/groups/%s/members?$select=%s", baseURL, url.PathEscape(g.ID), graphUserSelect))
+			if err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+
+			for _, m := range members {
+				// membros que não são usuários (grupos aninhados, service principals, dispositivos) são ignorados
+				if m.ODataType != "" && m.ODataType != "#microsoft.graph.user" {
+					continue
+				}
+				group.Members = append(group.Members, &entity.AzureDirectoryUser{
+					ID:                m.ID,
+					DisplayName:       m.DisplayName,
+					UserPrincipalName: m.UserPrincipalName,
+					Mail:              m.Mail,
+				})
+			}
+
+			result = append(result, group)
+		}
+	}
+
+	return result, nil
+}
+
+// findDirectoryGroups
+// Busca o grupo pelo object ID ou, nos demais casos, pelo displayName ou mailNickname
+func (a *AzureRepository) findDirectoryGroups(ctx context.Context, baseURL, token, name string) ([]graphObject, error) {
+
+	if graphObjectID.MatchString(name) {
+		var group graphObject
+		status, err := a.graphGet(ctx, token, fmt.Sprintf("%s/groups/%s?$select=%s", baseURL, url.PathEscape(name), graphGroupSelect), &group)
+		if status == http.StatusNotFound {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []graphObject{group}, nil
+	}
+
+	escaped := strings.ReplaceAll(name, "'", "''")
+	filter := fmt.Sprintf("displayName eq '%s' or mailNickname eq '%s'", escaped, escaped)
+	return a.graphList(ctx, token, fmt.Sprintf("%s/groups?$filter=%s&$select=%s", baseURL, url.QueryEscape(filter), graphGroupSelect))
+}
+
+// graphList
+// Percorre as páginas da coleção seguindo o @odata.nextLink
+func (a *AzureRepository) graphList(ctx context.Context, token, next string) ([]graphObject, error) {
+
+	var result []graphObject
+	for next != "" {
+		var page graphPage
+		if _, err := a.graphGet(ctx, token, next, &page); err != nil {
+			return nil, err
+		}
+		result = append(result, page.Value...)
+		next = page.NextLink
+	}
+	return result, nil
+}
+
+// graphGet
+// GET autenticado no Graph pelo transporte throttled da conta (TLS, proxy, orçamento e retry de 429/503).
+// Retorna o status HTTP junto com o erro
+func (a *AzureRepository) graphGet(ctx context.Context, token, endpoint string, v interface{}) (int, error) {
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := a.Throttled.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to call microsoft graph: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, fmt.Errorf("microsoft graph returned %d for %s: %s", resp.StatusCode, req.URL.Path, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.Unmarshal(body, v); err != nil {
+		return resp.StatusCode, fmt.Errorf("failed to decode microsoft graph response: %w", err)
+	}
+	return resp.StatusCode, nil
+}
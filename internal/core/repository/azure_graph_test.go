@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// staticCredential
+// Credencial com token fixo para os fakes locais
+type staticCredential struct{}
+
+func (staticCredential) GetToken(context.Context, policy.TokenRequestOptions) (azcore.AccessToken, error) {
+	return azcore.AccessToken{Token: "token", ExpiresOn: time.Now().Add(time.Hour)}, nil
+}
+
+// fakeGraph
+// Microsoft Graph local. O grupo platform tem os membros em duas páginas e a primeira
+// requisição de cada caminho recebe 429 quando throttle está ligado
+type fakeGraph struct {
+	mu       sync.Mutex
+	throttle bool
+	seen     map[string]int
+	server   *httptest.Server
+}
+
+func newFakeGraph(t *testing.T, throttle bool) *fakeGraph {
+	t.Helper()
+
+	f := &fakeGraph{throttle: throttle, seen: make(map[string]int)}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		f.mu.Lock()
+		f.seen[r.URL.String()]++
+		first := f.seen[r.URL.String()] == 1
+		f.mu.Unlock()
+
+		if f.throttle && first {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/v1.0/groups":
+			value := []map[string]string{}
+			if strings.Contains(r.URL.Query().Get("$filter"), "'platform'") {
+				value = append(value, map[string]string{"id": "group-1", "displayName": "platform"})
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"value": value})
+		case r.URL.Path == "/v1.0/groups/group-1/members" && r.URL.Query().Get("page") == "":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"value": []map[string]string{
+					{"@odata.type": "#microsoft.graph.user", "id": "user-1", "userPrincipalName": "ana@example.com"},
+					{"@odata.type": "#microsoft.graph.group", "id": "group-2"},
+				},
+				"@odata.nextLink": f.server.URL + "/v1.0/groups/group-1/members?page=2",
+			})
+		case r.URL.Path == "/v1.0/groups/group-1/members":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"value": []map[string]string{{"@odata.type": "#microsoft.graph.user", "id": "user-2", "userPrincipalName": "bia@example.com"}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeGraph) repository(groups ...string) *AzureRepository {
+
+	provider := entity.AzureProvider{
+		Graph:      entity.AzureGraph{BaseURL: f.server.URL + "/v1.0", Groups: groups},
+		Throttling: entity.AzureThrottling{MinBackoff: time.Millisecond, MaxBackoff: time.Millisecond, MaxRetryAfter: time.Millisecond},
+	}
+	transport := f.server.Client()
+	return &AzureRepository{
+		Provider:   provider,
+		Credential: staticCredential{},
+		Tracer:     testTracer(),
+		Transport:  transport,
+		Throttled:  newAzureThrottledTransport(transport, "test", provider.Throttling),
+	}
+}
+
+func TestAzureRepositoryListDirectoryGroups(t *testing.T) {
+
+	tests := []struct {
+		name        string
+		groups      []string
+		throttle    bool
+		wantGroups  int
+		wantMembers []string
+	}{
+		{name: "graph disabled"},
+		{name: "group by name", groups: []string{"platform"}, wantGroups: 1, wantMembers: []string{"user-1", "user-2"}},
+		{name: "throttled requests are retried", groups: []string{"platform"}, throttle: true, wantGroups: 1, wantMembers: []string{"user-1", "user-2"}},
+		{name: "unknown group", groups: []string{"data"}},
+		{name: "unknown object id", groups: []string{"00000000-0000-0000-0000-000000000000"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGraph(t, tt.throttle)
+
+			groups, err := fake.repository(tt.groups...).ListDirectoryGroups(context.Background())
+			if err != nil {
+				t.Fatalf("ListDirectoryGroups() error = %v", err)
+			}
+			if len(groups) != tt.wantGroups {
+				t.Fatalf("ListDirectoryGroups() = %d groups, want %d", len(groups), tt.wantGroups)
+			}
+			if tt.wantGroups == 0 {
+				return
+			}
+
+			var members []string
+			for _, member := range groups[0].Members {
+				members = append(members, member.ID)
+			}
+			if strings.Join(members, ",") != strings.Join(tt.wantMembers, ",") {
+				t.Errorf("ListDirectoryGroups() members = %v, want %v", members, tt.wantMembers)
+			}
+		})
+	}
+}
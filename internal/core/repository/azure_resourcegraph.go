@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/to"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resourcegraph/armresourcegraph"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// AzureResourceGraphRepository
+// Backend que lista os recursos pelo Azure Resource Graph em vez do armresources.Client.
+// Consulta todas as assinaturas em uma única query e retorna os campos completos do recurso
+type AzureResourceGraphRepository struct {
+	*AzureRepository
+}
+
+// resourceGraphPageSize máximo de linhas retornadas por página pelo Resource Graph
+const resourceGraphPageSize int32 = 1000
+
+// resourceGraphChanges
+// A tabela Resources não tem createdTime e changedTime. As datas vêm da tabela resourcechanges,
+// que guarda apenas os últimos 14 dias: recursos sem alteração no período ficam sem as datas
+const resourceGraphChanges = `| extend resourceId = tolower(id)
+| join kind=leftouter (resourcechanges
+	| extend resourceId = tolower(tostring(properties.targetResourceId)), changeType = tostring(properties.changeType), changeTime = todatetime(properties.changeAttributes.timestamp)
+	| summarize createdTime = min(iff(changeType == 'Create', changeTime, datetime(null))), changedTime = max(changeTime) by resourceId) on resourceId`
+
+// resourceGraphProjection colunas convertidas em armresources.GenericResourceExpanded
+const resourceGraphProjection = "| project id, name, type, kind, location, managedBy, tags, sku, plan, identity, properties, createdTime, changedTime"
+
+// Query
+// Executa uma consulta KQL nas assinaturas coletadas, percorrendo todas as páginas
+func (a *AzureRepository) Query(ctx context.Context, query string) ([]map[string]interface{}, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.Query")
+	defer span.End()
+
+	var result []map[string]interface{}
+	err := a.queryPages(ctxSpan, query, func(rows []map[string]interface{}) bool {
+		result = append(result, rows...)
+		return true
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// queryPages
+// Executa a consulta seguindo o skipToken e chama page a cada página. page retorna false para interromper
+func (a *AzureRepository) queryPages(ctx context.Context, query string, page func(rows []map[string]interface{}) bool) error {
+
+	subscriptions := make([]*string, 0, len(a.Clients))
+	for _, id := range a.subscriptionIDs() {
+		subscriptions = append(subscriptions, to.Ptr(id))
+	}
+
+	var skipToken *string
+	for {
+		resp, err := a.Graph.Resources(ctx, armresourcegraph.QueryRequest{
+			Query:         to.Ptr(query),
+			Subscriptions: subscriptions,
+			Options: &armresourcegraph.QueryRequestOptions{
+				ResultFormat: to.Ptr(armresourcegraph.ResultFormatObjectArray),
+				Top:          to.Ptr(resourceGraphPageSize),
+				SkipToken:    skipToken,
+			},
+		}, nil)
+		if err != nil {
+			return fmt.Errorf("failed to query resource graph: %w", err)
+		}
+
+		data, ok := resp.Data.([]interface{})
+		if !ok {
+			return fmt.Errorf("unexpected resource graph result format %T", resp.Data)
+		}
+
+		rows := make([]map[string]interface{}, 0, len(data))
+		for _, row := range data {
+			if v, ok := row.(map[string]interface{}); ok {
+				rows = append(rows, v)
+			}
+		}
+
+		if !page(rows) {
+			return ctx.Err()
+		}
+
+		if resp.SkipToken == nil || *resp.SkipToken == "" {
+			return nil
+		}
+		skipToken = resp.SkipToken
+	}
+}
+
+// resourcePropertiesBatch quantidade de IDs por consulta de propriedades
+const resourcePropertiesBatch = 200
+
+// ListResourceProperties
+// Propriedades dos recursos pelo Resource Graph, em lotes, indexadas pelo ID em minúsculas.
+// Substitui um GET por recurso quando a listagem do backend arm não retorna properties
+func (a *AzureRepository) ListResourceProperties(ctx context.Context, ids []string) (map[string]interface{}, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureRepository.ListResourceProperties")
+	defer span.End()
+
+	result := make(map[string]interface{}, len(ids))
+	for start := 0; start < len(ids); start += resourcePropertiesBatch {
+		batch := ids[start:min(start+resourcePropertiesBatch, len(ids))]
+
+		quoted := make([]string, 0, len(batch))
+		for _, id := range batch {
+			quoted = append(quoted, fmt.Sprintf("'%s'", kqlEscape(id)))
+		}
+		query := fmt.Sprintf("Resources | where id in~ (%s) | project id, properties", strings.Join(quoted, ", "))
+
+		err := a.queryPages(ctxSpan, query, func(rows []map[string]interface{}) bool {
+			for _, row := range rows {
+				if id, ok := row["id"].(string); ok {
+					result[strings.ToLower(id)] = row["properties"]
+				}
+			}
+			return true
+		})
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// PageResources
+// Mesma semântica do backend arm, com uma página do Resource Graph por página do canal
+func (a *AzureResourceGraphRepository) PageResources(ctx context.Context, filter *entity.AzureResourceFilter) <-chan entity.AzureResourcePage {
+
+	query := resourceGraphQuery(filter)
+
+	pages := make(chan entity.AzureResourcePage)
+	go func() {
+		defer close(pages)
+
+		ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureResourceGraphRepository.PageResources")
+		defer span.End()
+
+		var convertErr error
+		err := a.queryPages(ctxSpan, query, func(rows []map[string]interface{}) bool {
+			resources, err := parseResourceGraphRows(rows)
+			if err != nil {
+				convertErr = err
+				return false
+			}
+			return sendPage(ctxSpan, pages, entity.AzureResourcePage{Resources: resources})
+		})
+		if convertErr != nil {
+			err = convertErr
+		}
+
+		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+			return
+		}
+		if err != nil {
+			span.RecordError(err)
+			sendPage(ctxSpan, pages, entity.AzureResourcePage{Err: err})
+		}
+	}()
+
+	return pages
+}
+
+func (a *AzureResourceGraphRepository) ListResources(ctx context.Context) ([]*armresources.GenericResourceExpanded, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureResourceGraphRepository.ListResources")
+	defer span.End()
+
+	return collectPages(ctxSpan, a.PageResources(ctxSpan, &entity.AzureResourceFilter{}))
+}
+
+func (a *AzureResourceGraphRepository) ListResourcesByTag(ctx context.Context, tagKey, tagValue string) ([]*armresources.GenericResourceExpanded, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureResourceGraphRepository.ListResourcesByTag")
+	defer span.End()
+
+	return collectPages(ctxSpan, a.PageResources(ctxSpan, &entity.AzureResourceFilter{
+		TagKey:   tagKey,
+		TagValue: tagValue,
+	}))
+}
+
+func (a *AzureResourceGraphRepository) ListResourcesByResourceGroup(ctx context.Context, rsg string) ([]*armresources.GenericResourceExpanded, error) {
+	ctxSpan, span := a.Tracer.Tracer.Start(ctx, "AzureResourceGraphRepository.ListResourcesByResourceGroup")
+	defer span.End()
+
+	return collectPages(ctxSpan, a.PageResources(ctxSpan, &entity.AzureResourceFilter{
+		ResourceGroup: rsg,
+	}))
+}
+
+// resourceGraphQuery
+// Consulta KQL do filtro. O filtro de tempo é aplicado no servidor sobre as datas da tabela resourcechanges
+func resourceGraphQuery(filter *entity.AzureResourceFilter) string {
+
+	query := "Resources"
+	if len(filter.Subscriptions) > 0 {
+		ids := make([]string, 0, len(filter.Subscriptions))
+		for _, id := range filter.Subscriptions {
+			ids = append(ids, fmt.Sprintf("'%s'", kqlEscape(id)))
+		}
+		query = fmt.Sprintf("%s | where subscriptionId in~ (%s)", query, strings.Join(ids, ", "))
+	}
+	if filter.ResourceGroup != "" {
+		query = fmt.Sprintf("%s | where resourceGroup =~ '%s'", query, kqlEscape(filter.ResourceGroup))
+	}
+	if filter.TagKey != "" {
+		query = fmt.Sprintf("%s | where tags['%s'] =~ '%s'", query, kqlEscape(filter.TagKey), kqlEscape(filter.TagValue))
+	}
+	query = fmt.Sprintf("%s %s", query, resourceGraphChanges)
+	if !filter.Since.IsZero() {
+		since := filter.Since.UTC().Format(time.RFC3339)
+		query = fmt.Sprintf("%s | where createdTime >= datetime(%s) or changedTime >= datetime(%s)", query, since, since)
+	}
+
+	return fmt.Sprintf("%s %s", query, resourceGraphProjection)
+}
+
+// parseResourceGraphRows
+// Converte as linhas da consulta no mesmo modelo retornado pelo backend arm
+func parseResourceGraphRows(rows []map[string]interface{}) ([]*armresources.GenericResourceExpanded, error) {
+
+	result := make([]*armresources.GenericResourceExpanded, 0, len(rows))
+	for _, row := range rows {
+		data, err := json.Marshal(row)
+		if err != nil {
+			return nil, err
+		}
+
+		var resource armresources.GenericResourceExpanded
+		if err := json.Unmarshal(data, &resource); err != nil {
+			return nil, err
+		}
+
+		if properties, ok := resource.Properties.(map[string]interface{}); ok {
+			if state, ok := properties["provisioningState"].(string); ok {
+				resource.ProvisioningState = to.Ptr(state)
+			}
+		}
+
+		result = append(result, &resource)
+	}
+
+	return result, nil
+}
+
+// kqlEscape
+// Escapa o valor usado dentro de uma string KQL entre aspas simples
+func kqlEscape(value string) string {
+	return strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+}
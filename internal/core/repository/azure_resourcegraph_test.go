@@ -0,0 +1,89 @@
+package repository
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+func TestKqlEscape(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain", value: "rg-app", want: "rg-app"},
+		{name: "quote", value: "o'brien", want: `o\'brien`},
+		{name: "backslash", value: `a\b`, want: `a\\b`},
+		{name: "escaped quote", value: `x\' | project secrets`, want: `x\\\' | project secrets`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := kqlEscape(tt.value); got != tt.want {
+				t.Errorf("kqlEscape(%q) = %q, want %q", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceGraphQuery(t *testing.T) {
+
+	since := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("BRT", -3*60*60))
+
+	tests := []struct {
+		name     string
+		filter   entity.AzureResourceFilter
+		contains []string
+		excludes []string
+	}{
+		{
+			name:     "no filter",
+			filter:   entity.AzureResourceFilter{},
+			contains: []string{"Resources ", resourceGraphChanges, resourceGraphProjection},
+			excludes: []string{"subscriptionId in~", "resourceGroup =~", "tags[", "createdTime >="},
+		},
+		{
+			name:     "subscriptions",
+			filter:   entity.AzureResourceFilter{Subscriptions: []string{"sub-1", "sub'2"}},
+			contains: []string{`| where subscriptionId in~ ('sub-1', 'sub\'2')`},
+		},
+		{
+			name:     "resource group",
+			filter:   entity.AzureResourceFilter{ResourceGroup: "rg'app"},
+			contains: []string{`| where resourceGroup =~ 'rg\'app'`},
+		},
+		{
+			name:     "tag",
+			filter:   entity.AzureResourceFilter{TagKey: "owner", TagValue: `team\x`},
+			contains: []string{`| where tags['owner'] =~ 'team\\x'`},
+		},
+		{
+			name:     "since is pushed to the server in UTC",
+			filter:   entity.AzureResourceFilter{Since: since},
+			contains: []string{"| where createdTime >= datetime(2024-05-01T15:00:00Z) or changedTime >= datetime(2024-05-01T15:00:00Z)"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query := resourceGraphQuery(&tt.filter)
+			for _, part := range tt.contains {
+				if !strings.Contains(query, part) {
+					t.Errorf("resourceGraphQuery() = %q, want it to contain %q", query, part)
+				}
+			}
+			for _, part := range tt.excludes {
+				if strings.Contains(query, part) {
+					t.Errorf("resourceGraphQuery() = %q, want it not to contain %q", query, part)
+				}
+			}
+			if !strings.HasSuffix(query, resourceGraphProjection) {
+				t.Errorf("resourceGraphQuery() = %q, want the projection at the end", query)
+			}
+		})
+	}
+}
@@ -0,0 +1,226 @@
+package repository
+
+import (
+	"context"
+	"io"
+	"math/rand"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+	"golang.org/x/time/rate"
+)
+
+var azureThrottledRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "cloud_collector_azure_throttled_requests_total",
+	Help: "Azure Resource Manager and Microsoft Graph responses with HTTP 429 or 503.",
+}, []string{"account", "subscription", "status"})
+
+var azureRetries = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "cloud_collector_azure_retries_total",
+	Help: "Azure Resource Manager and Microsoft Graph requests retried after throttling.",
+}, []string{"account", "subscription"})
+
+var azureBudgetWait = prometheus.NewCounterVec(prometheus.CounterOpts{
+	Name: "cloud_collector_azure_budget_wait_seconds_total",
+	Help: "Time spent waiting for the per-subscription request budget.",
+}, []string{"account", "subscription"})
+
+var azureRateLimitRemaining = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+	Name: "cloud_collector_azure_ratelimit_remaining",
+	Help: "Last x-ms-ratelimit-remaining-* value returned by Azure Resource Manager.",
+}, []string{"account", "subscription", "header"})
+
+func init() {
+	prometheus.MustRegister(azureThrottledRequests, azureRetries, azureBudgetWait, azureRateLimitRemaining)
+}
+
+// azureRetryStatusCodes
+// Status repetidos pelo retry do azcore. 429 e 503 ficam com o azureThrottledTransport
+var azureRetryStatusCodes = []int{
+	http.StatusRequestTimeout,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusGatewayTimeout,
+}
+
+var subscriptionPath = regexp.MustCompile(`(?i)/subscriptions/([^/?]+)`)
+
+// azureThrottledTransport
+// Transporte compartilhado pelos clients ARM e pelas chamadas ao Graph de uma conta.
+// Aplica um token bucket por assinatura (tenant para o Graph e chamadas fora de assinatura)
+// e repete as respostas 429/503 respeitando o Retry-After ou, sem o header, com backoff exponencial e jitter
+type azureThrottledTransport struct {
+	next       policy.Transporter
+	account    string
+	throttling entity.AzureThrottling
+
+	mu       sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func newAzureThrottledTransport(next policy.Transporter, account string, throttling entity.AzureThrottling) *azureThrottledTransport {
+	return &azureThrottledTransport{
+		next:       next,
+		account:    account,
+		throttling: throttling,
+		limiters:   make(map[string]*rate.Limiter),
+	}
+}
+
+func (t *azureThrottledTransport) Do(req *http.Request) (*http.Response, error) {
+
+	subscription := "tenant"
+	if match := subscriptionPath.FindStringSubmatch(req.URL.Path); match != nil {
+		subscription = strings.ToLower(match[1])
+	}
+
+	for attempt := 0; ; attempt++ {
+		if err := t.wait(req.Context(), subscription); err != nil {
+			return nil, err
+		}
+
+		if attempt > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = body
+		}
+
+		resp, err := t.next.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		t.observe(resp, subscription)
+
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+			return resp, nil
+		}
+
+		azureThrottledRequests.WithLabelValues(t.account, subscription, strconv.Itoa(resp.StatusCode)).Inc()
+		if attempt >= t.throttling.GetMaxRetries() {
+			return resp, nil
+		}
+
+		delay, ok := retryAfter(resp.Header)
+		if ok {
+			delay = clampDelay(delay, t.throttling.GetMinBackoff(), t.throttling.GetMaxRetryAfter())
+		} else {
+			delay = t.backoff(attempt)
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		azureRetries.WithLabelValues(t.account, subscription).Inc()
+		if err := sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// wait
+// Aguarda o orçamento de requisições da assinatura
+func (t *azureThrottledTransport) wait(ctx context.Context, subscription string) error {
+
+	t.mu.Lock()
+	limiter, ok := t.limiters[subscription]
+	if !ok {
+		limiter = rate.NewLimiter(rate.Limit(t.throttling.GetRequestsPerSecond()), t.throttling.GetBurst())
+		t.limiters[subscription] = limiter
+	}
+	t.mu.Unlock()
+
+	start := time.Now()
+	err := limiter.Wait(ctx)
+	if waited := time.Since(start); waited > time.Millisecond {
+		azureBudgetWait.WithLabelValues(t.account, subscription).Add(waited.Seconds())
+	}
+	return err
+}
+
+// observe
+// Publica os headers x-ms-ratelimit-remaining-* da resposta
+func (t *azureThrottledTransport) observe(resp *http.Response, subscription string) {
+	for name, values := range resp.Header {
+		name = strings.ToLower(name)
+		if !strings.HasPrefix(name, "x-ms-ratelimit-remaining-") || len(values) == 0 {
+			continue
+		}
+		if v, err := strconv.ParseFloat(values[0], 64); err == nil {
+			azureRateLimitRemaining.WithLabelValues(t.account, subscription, name).Set(v)
+		}
+	}
+}
+
+// backoff
+// Exponencial a partir de MinBackoff, limitado a MaxBackoff, com jitter na metade superior do intervalo
+func (t *azureThrottledTransport) backoff(attempt int) time.Duration {
+
+	delay := t.throttling.GetMinBackoff() << attempt
+	if delay <= 0 || delay > t.throttling.GetMaxBackoff() {
+		delay = t.throttling.GetMaxBackoff()
+	}
+
+	half := delay / 2
+	return half + time.Duration(rand.Int63n(int64(half)+1))
+}
+
+// retryAfter
+// Lê retry-after-ms, x-ms-retry-after-ms e Retry-After (segundos ou data HTTP)
+func retryAfter(header http.Header) (time.Duration, bool) {
+
+	for _, name := range []string{"retry-after-ms", "x-ms-retry-after-ms"} {
+		if v, err := strconv.Atoi(header.Get(name)); err == nil && v > 0 {
+			return time.Duration(v) * time.Millisecond, true
+		}
+	}
+
+	value := header.Get("Retry-After")
+	if value == "" {
+		return 0, false
+	}
+	if v, err := strconv.Atoi(value); err == nil && v >= 0 {
+		return time.Duration(v) * time.Second, true
+	}
+	if at, err := http.ParseTime(value); err == nil {
+		return time.Until(at), true
+	}
+	return 0, false
+}
+
+// clampDelay
+// Mantém a espera do Retry-After entre lower e upper. Retry-After: 0 ou uma data passada não repetem imediatamente
+// e um valor alto não bloqueia a sincronização indefinidamente
+func clampDelay(delay, lower, upper time.Duration) time.Duration {
+	if delay < lower {
+		return lower
+	}
+	if delay > upper {
+		return upper
+	}
+	return delay
+}
+
+func sleep(ctx context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfter(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		header map[string]string
+		want   time.Duration
+		wantOK bool
+	}{
+		{name: "missing", header: nil},
+		{name: "seconds", header: map[string]string{"Retry-After": "30"}, want: 30 * time.Second, wantOK: true},
+		{name: "zero seconds", header: map[string]string{"Retry-After": "0"}, want: 0, wantOK: true},
+		{name: "negative seconds", header: map[string]string{"Retry-After": "-1"}},
+		{name: "invalid value", header: map[string]string{"Retry-After": "soon"}},
+		{name: "milliseconds", header: map[string]string{"retry-after-ms": "1500"}, want: 1500 * time.Millisecond, wantOK: true},
+		{name: "ms header", header: map[string]string{"x-ms-retry-after-ms": "250"}, want: 250 * time.Millisecond, wantOK: true},
+		{
+			name:   "milliseconds win over seconds",
+			header: map[string]string{"retry-after-ms": "100", "Retry-After": "30"},
+			want:   100 * time.Millisecond,
+			wantOK: true,
+		},
+		{
+			name:   "invalid milliseconds fall back to seconds",
+			header: map[string]string{"retry-after-ms": "0", "Retry-After": "2"},
+			want:   2 * time.Second,
+			wantOK: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			for k, v := range tt.header {
+				header.Set(k, v)
+			}
+
+			got, ok := retryAfter(header)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("retryAfter() = (%v, %v), want (%v, %v)", got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestRetryAfterDate(t *testing.T) {
+
+	tests := []struct {
+		name string
+		at   time.Time
+		min  time.Duration
+		max  time.Duration
+	}{
+		{name: "future date", at: time.Now().Add(time.Minute), min: 50 * time.Second, max: time.Minute},
+		{name: "past date", at: time.Now().Add(-time.Minute), min: -2 * time.Minute, max: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("Retry-After", tt.at.UTC().Format(http.TimeFormat))
+
+			got, ok := retryAfter(header)
+			if !ok || got < tt.min || got > tt.max {
+				t.Errorf("retryAfter() = (%v, %v), want between %v and %v", got, ok, tt.min, tt.max)
+			}
+		})
+	}
+}
+
+func TestClampDelay(t *testing.T) {
+
+	lower, upper := time.Second, 2*time.Minute
+
+	tests := []struct {
+		name  string
+		delay time.Duration
+		want  time.Duration
+	}{
+		{name: "zero", delay: 0, want: lower},
+		{name: "past date", delay: -time.Minute, want: lower},
+		{name: "inside the limits", delay: 30 * time.Second, want: 30 * time.Second},
+		{name: "above the limit", delay: time.Hour, want: upper},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := clampDelay(tt.delay, lower, upper); got != tt.want {
+				t.Errorf("clampDelay(%v) = %v, want %v", tt.delay, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+	"github.com/synera-br/golang-cloud-collector/pkg/otelpkg"
+	cloudasset "google.golang.org/api/cloudasset/v1"
+	"google.golang.org/api/cloudresourcemanager/v3"
+	"google.golang.org/api/option"
+)
+
+type GCPRepository struct {
+	Provider        entity.GCPProvider
+	Tracer          *otelpkg.OtelPkgInstrument
+	Asset           *cloudasset.Service
+	ResourceManager *cloudresourcemanager.Service
+}
+
+func NewGCPRepository(provider *entity.GCPProvider, otl *otelpkg.OtelPkgInstrument) (entity.GCPProviderInterface, error) {
+
+	p := &GCPRepository{
+		Provider: *provider,
+		Tracer:   otl,
+	}
+
+	ctxSpan, span := p.Tracer.Tracer.Start(context.Background(), "GCPRepository.NewGCPRepository")
+	defer span.End()
+
+	if err := p.Connection(ctxSpan, &p.Provider); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (g *GCPRepository) Connection(ctx context.Context, provider *entity.GCPProvider) error {
+
+	ctxSpan, span := g.Tracer.Tracer.Start(ctx, "GCPRepository.Connection")
+	defer span.End()
+
+	var err error
+
+	g.Asset, err = cloudasset.NewService(ctxSpan, g.clientOptions(provider.Endpoint)...)
+	if err != nil {
+		return fmt.Errorf("failed to create cloud asset client: %w", err)
+	}
+
+	g.ResourceManager, err = cloudresourcemanager.NewService(ctxSpan, g.clientOptions(provider.ResourceManagerEndpoint)...)
+	if err != nil {
+		return fmt.Errorf("failed to create resource manager client: %w", err)
+	}
+
+	return nil
+}
+
+// clientOptions
+// Sem arquivo de credencial e com endpoint alternativo a autenticação é desabilitada,
+// permitindo usar um serviço local
+func (g *GCPRepository) clientOptions(endpoint string) []option.ClientOption {
+
+	var opts []option.ClientOption
+	if g.Provider.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(g.Provider.CredentialsFile))
+	}
+
+	if endpoint != "" {
+		opts = append(opts, option.WithEndpoint(endpoint))
+		if g.Provider.CredentialsFile == "" {
+			opts = append(opts, option.WithoutAuthentication())
+		}
+	}
+
+	return opts
+}
+
+func (g *GCPRepository) GetProject(ctx context.Context, name string) (*entity.GCPProject, error) {
+	ctxSpan, span := g.Tracer.Tracer.Start(ctx, "GCPRepository.GetProject")
+	defer span.End()
+
+	project, err := g.ResourceManager.Projects.Get(name).Context(ctxSpan).Do()
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get project: %w", err)
+	}
+
+	return parseGCPProject(project), nil
+}
+
+func (g *GCPRepository) GetFolder(ctx context.Context, name string) (*entity.GCPFolder, error) {
+	ctxSpan, span := g.Tracer.Tracer.Start(ctx, "GCPRepository.GetFolder")
+	defer span.End()
+
+	folder, err := g.ResourceManager.Folders.Get(name).Context(ctxSpan).Do()
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to get folder: %w", err)
+	}
+
+	return parseGCPFolder(folder), nil
+}
+
+func (g *GCPRepository) ListProjects(ctx context.Context) ([]*entity.GCPProject, error) {
+	ctxSpan, span := g.Tracer.Tracer.Start(ctx, "GCPRepository.ListProjects")
+	defer span.End()
+
+	var result []*entity.GCPProject
+
+	if len(g.Provider.Projects) > 0 {
+		for _, id := range g.Provider.Projects {
+			project, err := g.GetProject(ctxSpan, fmt.Sprintf("projects/%s", id))
+			if err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+			result = append(result, project)
+		}
+		return result, nil
+	}
+
+	err := g.ResourceManager.Projects.Search().Query("state:ACTIVE").Pages(ctxSpan, func(page *cloudresourcemanager.SearchProjectsResponse) error {
+		for _, project := range page.Projects {
+			result = append(result, parseGCPProject(project))
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list projects: %w", err)
+	}
+
+	return result, nil
+}
+
+func (g *GCPRepository) ListFolders(ctx context.Context) ([]*entity.GCPFolder, error) {
+	ctxSpan, span := g.Tracer.Tracer.Start(ctx, "GCPRepository.ListFolders")
+	defer span.End()
+
+	var result []*entity.GCPFolder
+
+	err := g.ResourceManager.Folders.Search().Query("state:ACTIVE").Pages(ctxSpan, func(page *cloudresourcemanager.SearchFoldersResponse) error {
+		for _, folder := range page.Folders {
+			result = append(result, parseGCPFolder(folder))
+		}
+		return nil
+	})
+	if err != nil {
+		span.RecordError(err)
+		return nil, fmt.Errorf("failed to list folders: %w", err)
+	}
+
+	return result, nil
+}
+
+func (g *GCPRepository) ListResources(ctx context.Context) ([]*entity.GCPResource, error) {
+	ctxSpan, span := g.Tracer.Tracer.Start(ctx, "GCPRepository.ListResources")
+	defer span.End()
+
+	return g.searchResources(ctxSpan, "")
+}
+
+func (g *GCPRepository) ListResourcesByProject(ctx context.Context, project string) ([]*entity.GCPResource, error) {
+	ctxSpan, span := g.Tracer.Tracer.Start(ctx, "GCPRepository.ListResourcesByProject")
+	defer span.End()
+
+	result, err := g.searchScope(ctxSpan, fmt.Sprintf("projects/%s", project), "")
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (g *GCPRepository) ListResourcesByLabel(ctx context.Context, labelKey, labelValue string) ([]*entity.GCPResource, error) {
+	ctxSpan, span := g.Tracer.Tracer.Start(ctx, "GCPRepository.ListResourcesByLabel")
+	defer span.End()
+
+	return g.searchResources(ctxSpan, fmt.Sprintf("labels.%s:%s", labelKey, gcpQueryValue(labelValue)))
+}
+
+// gcpQueryValue
+// Valor entre aspas na sintaxe de consulta do Cloud Asset, para que espaços e : não quebrem a consulta
+func gcpQueryValue(value string) string {
+	return fmt.Sprintf(`"%s"`, strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value))
+}
+
+// searchResources
+// Com organização configurada e sem lista de projetos a busca é feita em uma única chamada,
+// senão percorre cada projeto retornado por ListProjects, que respeita a lista configurada
+func (g *GCPRepository) searchResources(ctx context.Context, query string) ([]*entity.GCPResource, error) {
+
+	if g.Provider.Organization != "" && len(g.Provider.Projects) == 0 {
+		return g.searchScope(ctx, g.Provider.Organization, query)
+	}
+
+	projects, err := g.ListProjects(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*entity.GCPResource
+	for _, project := range projects {
+		resources, err := g.searchScope(ctx, fmt.Sprintf("projects/%s", project.ProjectID), query)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, resources...)
+	}
+
+	return result, nil
+}
+
+func (g *GCPRepository) searchScope(ctx context.Context, scope, query string) ([]*entity.GCPResource, error) {
+
+	var result []*entity.GCPResource
+
+	call := g.Asset.V1.SearchAllResources(scope)
+	if query != "" {
+		call = call.Query(query)
+	}
+
+	err := call.Pages(ctx, func(page *cloudasset.SearchAllResourcesResponse) error {
+		for _, r := range page.Results {
+			result = append(result, &entity.GCPResource{
+				Name:        r.Name,
+				DisplayName: r.DisplayName,
+				AssetType:   r.AssetType,
+				Project:     r.Project,
+				Folders:     r.Folders,
+				Location:    r.Location,
+				State:       r.State,
+				Labels:      r.Labels,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list resources: %w", err)
+	}
+
+	return result, nil
+}
+
+func parseGCPProject(project *cloudresourcemanager.Project) *entity.GCPProject {
+	return &entity.GCPProject{
+		Name:        project.Name,
+		ProjectID:   project.ProjectId,
+		DisplayName: project.DisplayName,
+		Parent:      project.Parent,
+		State:       project.State,
+		Labels:      project.Labels,
+	}
+}
+
+func parseGCPFolder(folder *cloudresourcemanager.Folder) *entity.GCPFolder {
+	return &entity.GCPFolder{
+		Name:        folder.Name,
+		DisplayName: folder.DisplayName,
+		Parent:      folder.Parent,
+		State:       folder.State,
+	}
+}
@@ -0,0 +1,186 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// fakeGCP
+// Cloud Asset e Resource Manager locais. Registra o escopo e a consulta de cada busca de recursos
+type fakeGCP struct {
+	mu       sync.Mutex
+	searches []string
+	server   *httptest.Server
+}
+
+func newFakeGCP(t *testing.T) *fakeGCP {
+	t.Helper()
+
+	f := &fakeGCP{}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := r.URL.Path
+
+		switch {
+		case strings.HasSuffix(path, ":searchAllResources"):
+			scope := strings.TrimSuffix(strings.TrimPrefix(path, "/v1/"), ":searchAllResources")
+			f.mu.Lock()
+			f.searches = append(f.searches, scope+"?"+r.URL.Query().Get("query"))
+			f.mu.Unlock()
+
+			// duas páginas por escopo
+			name, next := "vm-a", "page-2"
+			if r.URL.Query().Get("pageToken") == "page-2" {
+				name, next = "vm-b", ""
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"nextPageToken": next,
+				"results": []map[string]interface{}{{
+					"name":      "//compute.googleapis.com/" + scope + "/zones/us-east1-b/instances/" + name,
+					"assetType": "compute.googleapis.com/Instance",
+					"project":   "projects/100",
+					"labels":    map[string]string{"owner": "platform"},
+				}},
+			})
+		case path == "/v3/projects:search":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"projects": []map[string]interface{}{{"name": "projects/100", "projectId": "app-prod", "state": "ACTIVE"}},
+			})
+		case strings.HasPrefix(path, "/v3/projects/"):
+			id := strings.TrimPrefix(path, "/v3/projects/")
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"name": "projects/1" + id, "projectId": id, "parent": "folders/10", "state": "ACTIVE",
+			})
+		case path == "/v3/folders:search":
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"folders": []map[string]interface{}{{"name": "folders/10", "displayName": "apps", "parent": "organizations/1", "state": "ACTIVE"}},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(f.server.Close)
+	return f
+}
+
+func (f *fakeGCP) repository(t *testing.T, provider entity.GCPProvider) *GCPRepository {
+	t.Helper()
+
+	provider.Endpoint = f.server.URL + "/"
+	provider.ResourceManagerEndpoint = f.server.URL + "/"
+	repository, err := NewGCPRepository(&provider, testTracer())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return repository.(*GCPRepository)
+}
+
+func (f *fakeGCP) searchedScopes() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	seen := make(map[string]bool)
+	var result []string
+	for _, search := range f.searches {
+		if !seen[search] {
+			seen[search] = true
+			result = append(result, search)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func TestGCPRepositoryListResources(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		provider entity.GCPProvider
+		want     []string
+	}{
+		{
+			name:     "organization scope",
+			provider: entity.GCPProvider{Organization: "organizations/1"},
+			want:     []string{"organizations/1?"},
+		},
+		{
+			name:     "configured projects win over the organization",
+			provider: entity.GCPProvider{Organization: "organizations/1", Projects: []string{"app-prod", "app-dev"}},
+			want:     []string{"projects/app-dev?", "projects/app-prod?"},
+		},
+		{
+			name:     "projects visible to the credential",
+			provider: entity.GCPProvider{},
+			want:     []string{"projects/app-prod?"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGCP(t)
+
+			result, err := fake.repository(t, tt.provider).ListResources(context.Background())
+			if err != nil {
+				t.Fatalf("ListResources() error = %v", err)
+			}
+			if got := fake.searchedScopes(); strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("ListResources() searched %v, want %v", got, tt.want)
+			}
+			if want := 2 * len(tt.want); len(result) != want {
+				t.Errorf("ListResources() = %d resources, want %d", len(result), want)
+			}
+		})
+	}
+}
+
+func TestGCPRepositoryListResourcesByLabel(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		value string
+		want  string
+	}{
+		{name: "plain value", value: "platform", want: `labels.owner:"platform"`},
+		{name: "spaces", value: "data team", want: `labels.owner:"data team"`},
+		{name: "colon", value: "team:data", want: `labels.owner:"team:data"`},
+		{name: "quotes", value: `say "hi"\`, want: `labels.owner:"say \"hi\"\\"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeGCP(t)
+
+			if _, err := fake.repository(t, entity.GCPProvider{Organization: "organizations/1"}).ListResourcesByLabel(context.Background(), "owner", tt.value); err != nil {
+				t.Fatalf("ListResourcesByLabel() error = %v", err)
+			}
+			if got := fake.searchedScopes(); len(got) != 1 || got[0] != "organizations/1?"+tt.want {
+				t.Errorf("ListResourcesByLabel() searched %v, want organizations/1?%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGCPRepositoryProjectsAndFolders(t *testing.T) {
+
+	fake := newFakeGCP(t)
+	repository := fake.repository(t, entity.GCPProvider{Projects: []string{"app-prod"}})
+	ctx := context.Background()
+
+	projects, err := repository.ListProjects(ctx)
+	if err != nil || len(projects) != 1 || projects[0].ProjectID != "app-prod" || projects[0].Parent != "folders/10" {
+		t.Errorf("ListProjects() = %+v, %v", projects, err)
+	}
+
+	folders, err := repository.ListFolders(ctx)
+	if err != nil || len(folders) != 1 || folders[0].Name != "folders/10" || folders[0].Parent != "organizations/1" {
+		t.Errorf("ListFolders() = %+v, %v", folders, err)
+	}
+}
@@ -0,0 +1,12 @@
+package repository
+
+import (
+	"github.com/synera-br/golang-cloud-collector/pkg/otelpkg"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// testTracer
+// Tracer sem exportador para os testes
+func testTracer() *otelpkg.OtelPkgInstrument {
+	return &otelpkg.OtelPkgInstrument{Tracer: noop.NewTracerProvider().Tracer("test")}
+}
@@ -0,0 +1,313 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+	"github.com/synera-br/golang-cloud-collector/pkg/cache"
+	"github.com/synera-br/golang-cloud-collector/pkg/otelpkg"
+)
+
+type AWSServiceInterface interface {
+	entity.AWSProviderInterface
+	entity.CloudProviderInterface
+}
+
+type AWSService struct {
+	Repository entity.AWSProviderInterface
+	Cache      cache.CacheInterface
+	Tracer     *otelpkg.OtelPkgInstrument
+}
+
+const awsPrefix = "aws"
+
+func NewAWSService(provider *entity.AWSProviderInterface, cc *cache.CacheInterface, otl *otelpkg.OtelPkgInstrument) (AWSServiceInterface, error) {
+
+	return &AWSService{
+		Repository: *provider,
+		Cache:      *cc,
+		Tracer:     otl,
+	}, nil
+
+}
+
+func (s *AWSService) GetAccount(ctx context.Context, id string) (*entity.AWSAccount, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.GetAccount")
+	defer span.End()
+
+	var data entity.AWSAccount
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_account_%s", awsPrefix, id))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.getAccountFromRepository(ctxSpan, id)
+		return &data, nil
+	}
+
+	return s.getAccountFromRepository(ctxSpan, id)
+}
+
+func (s *AWSService) getAccountFromRepository(ctx context.Context, id string) (*entity.AWSAccount, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.getAccountFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.GetAccount(ctxSpan, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if v != nil {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_account_%s", awsPrefix, id), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+func (s *AWSService) ListResourcesByTag(ctx context.Context, tagKey, tagValue string) ([]*entity.AWSResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.ListResourcesByTag")
+	defer span.End()
+
+	var data []*entity.AWSResource
+
+	queryPrefix := fmt.Sprintf("%s_key_%s_value_%s", awsPrefix, tagKey, tagValue)
+	result, _ := s.Cache.Get(ctxSpan, queryPrefix)
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.listResourcesByTagFromRepository(ctxSpan, tagKey, tagValue)
+		return data, nil
+	}
+	return s.listResourcesByTagFromRepository(ctxSpan, tagKey, tagValue)
+}
+
+func (s *AWSService) listResourcesByTagFromRepository(ctx context.Context, tagKey, tagValue string) ([]*entity.AWSResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.listResourcesByTagFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.ListResourcesByTag(ctxSpan, tagKey, tagValue)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(v) > 0 {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_key_%s_value_%s", awsPrefix, tagKey, tagValue), serializedData, s.Cache.TTL(time.Second))
+	}
+	return v, nil
+}
+
+func (s *AWSService) ListResourcesByRegion(ctx context.Context, region string) ([]*entity.AWSResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.ListResourcesByRegion")
+	defer span.End()
+
+	var data []*entity.AWSResource
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_region_%s", awsPrefix, region))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		go s.listResourcesByRegionFromRepository(ctxSpan, region)
+		return data, nil
+	}
+	return s.listResourcesByRegionFromRepository(ctxSpan, region)
+}
+
+func (s *AWSService) listResourcesByRegionFromRepository(ctx context.Context, region string) ([]*entity.AWSResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.listResourcesByRegionFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.ListResourcesByRegion(ctxSpan, region)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(v) > 0 {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_region_%s", awsPrefix, region), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+func (s *AWSService) ListResources(ctx context.Context) ([]*entity.AWSResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.ListResources")
+	defer span.End()
+
+	var data []*entity.AWSResource
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_all_resources", awsPrefix))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.listResourcesFromRepository(ctxSpan)
+		return data, nil
+	}
+	return s.listResourcesFromRepository(ctxSpan)
+}
+
+func (s *AWSService) listResourcesFromRepository(ctx context.Context) ([]*entity.AWSResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.listResourcesFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.ListResources(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(v) > 0 {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_all_resources", awsPrefix), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+func (s *AWSService) Provider() entity.CloudProvider {
+	return entity.AWS
+}
+
+func (s *AWSService) Account() string {
+	return entity.DefaultAccount
+}
+
+func (s *AWSService) ListCloudResources(ctx context.Context) ([]entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.ListCloudResources")
+	defer span.End()
+
+	resources, err := s.ListResources(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return parseAWSCloudResources(resources), nil
+}
+
+func (s *AWSService) FilterCloudResources(ctx context.Context, trigger *entity.Trigger) ([]entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.FilterCloudResources")
+	defer span.End()
+
+	var err error = nil
+	var resources []*entity.AWSResource
+
+	if trigger.TargetResource.ResourceName != "" {
+		resources, err = s.ListResourcesByRegion(ctxSpan, trigger.TargetResource.ResourceName)
+	} else if trigger.TargetTags.Key != "" && trigger.TargetTags.Value != "" {
+		resources, err = s.ListResourcesByTag(ctxSpan, trigger.TargetTags.Key, trigger.TargetTags.Value)
+	} else {
+		resources, err = s.ListResources(ctxSpan)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return parseAWSCloudResources(resources), nil
+}
+
+// StreamCloudResources
+// O repositório não pagina a coleta; o resultado do filtro é entregue em uma página
+func (s *AWSService) StreamCloudResources(ctx context.Context, trigger *entity.Trigger) <-chan entity.CloudResourcePage {
+	return streamCloudResources(ctx, func(ctx context.Context) ([]entity.CloudResource, error) {
+		return s.FilterCloudResources(ctx, trigger)
+	})
+}
+
+// GetParent
+// Recurso -> conta
+func (s *AWSService) GetParent(ctx context.Context, resource *entity.CloudResource) (*entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AWSService.GetParent")
+	defer span.End()
+
+	if resource.ParentID == "" {
+		return nil, nil
+	}
+
+	account, err := s.GetAccount(ctxSpan, resource.ParentID)
+	if errors.Is(err, entity.ErrAccountNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return &entity.CloudResource{
+		ID:       account.ID,
+		Name:     account.ID,
+		Type:     "account",
+		Level:    entity.CloudResourceAccount,
+		Provider: "aws",
+		Account:  account.ID,
+		Annotations: map[string]string{
+			"account_arn": account.ARN,
+		},
+	}, nil
+}
+
+func parseAWSCloudResources(resources []*entity.AWSResource) []entity.CloudResource {
+	result := make([]entity.CloudResource, 0, len(resources))
+	for _, r := range resources {
+		resourceType := strings.ToLower(r.Type)
+		if resourceType == "" {
+			resourceType = strings.ToLower(r.Service)
+		}
+
+		result = append(result, entity.CloudResource{
+			ID:       r.ARN,
+			Name:     r.Name,
+			Type:     resourceType,
+			Family:   strings.ToLower(r.Service),
+			Level:    entity.CloudResourceItem,
+			Provider: "aws",
+			Account:  r.AccountID,
+			Location: r.Region,
+			ParentID: r.AccountID,
+			Tags:     r.Tags,
+			Annotations: map[string]string{
+				"resource_arn": r.ARN,
+			},
+		})
+	}
+	return result
+}
@@ -4,8 +4,11 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
@@ -15,22 +18,66 @@ import (
 
 type AzureServiceInterface interface {
 	entity.AzureProviderInterface
+	entity.CloudProviderInterface
+	ListResourcesSince(ctx context.Context, since time.Time) ([]*armresources.GenericResourceExpanded, error)
+	ResourceChanged(ctx context.Context, resourceID string, deleted bool) ([]entity.CloudResource, error)
+	ManagesSubscription(ctx context.Context, subscriptionID string) bool
+	ValidateEventSecret(secret string) bool
 }
 
 type AzureService struct {
 	Repository entity.AzureProviderInterface
 	Cache      cache.CacheInterface
 	Tracer     *otelpkg.OtelPkgInstrument
+	Name       string
+	Cloud      string
+	// Portal endereço do portal usado nos links das entidades
+	Portal   string
+	Rules    []entity.AzureRelationshipRule
+	Children []entity.AzureChildResource
+	// ManagementGroups assinaturas ligadas ao grupo de gerenciamento, que é emitido como Domain
+	ManagementGroups bool
+	// Directory grupos e usuários do Entra ID emitidos junto com os recursos
+	Directory bool
+	Ownership entity.AzureOwnership
+	// OwnerMapping regra de owner do backstage.mapping. Recursos com uma das tags não têm o owner inferido
+	OwnerMapping entity.BackstageMappingRule
+	// ActivityLog enriquecimento pelo criador do recurso
+	ActivityLog entity.AzureActivityLog
+	Incremental entity.AzureIncremental
+	EventGrid   entity.AzureEventGrid
+	prefix      string
+
+	// owners índice de ownership da última sincronização, reaproveitado por GetParent e pelos eventos
+	ownersMu sync.Mutex
+	owners   *ownershipIndex
 }
 
 const azurePrefix = "azure"
 
-func NewAzureService(provider *entity.AzureProviderInterface, cc *cache.CacheInterface, otl *otelpkg.OtelPkgInstrument) (AzureServiceInterface, error) {
+// NewAzureService
+// account configuração da conta Azure. O nome da conta é usado também como namespace das chaves de cache
+// mapping regras de mapeamento do Backstage, usadas para reconhecer as tags de owner
+func NewAzureService(account *entity.AzureProvider, mapping *entity.BackstageMapping, provider *entity.AzureProviderInterface, cc *cache.CacheInterface, otl *otelpkg.OtelPkgInstrument) (AzureServiceInterface, error) {
 
 	return &AzureService{
 		Repository: *provider,
 		Cache:      *cc,
 		Tracer:     otl,
+		Name:       account.GetName(),
+		Cloud:      account.GetCloud(),
+		Portal:     account.GetPortalURL(),
+		Rules:      append(append([]entity.AzureRelationshipRule{}, azureRelationshipRules...), account.Relationships...),
+		Children:   account.ChildResources,
+
+		ManagementGroups: account.ManagementGroups,
+		Directory:        account.Graph.Enabled(),
+		Ownership:        account.Ownership,
+		OwnerMapping:     mapping.GetOwner(),
+		ActivityLog:      account.ActivityLog,
+		Incremental:      account.Incremental,
+		EventGrid:        account.EventGrid,
+		prefix:           fmt.Sprintf("%s_%s", azurePrefix, account.GetName()),
 	}, nil
 
 }
@@ -40,7 +87,7 @@ func (s *AzureService) GetSubscription(ctx context.Context, name string, id stri
 	defer span.End()
 
 	var data armsubscriptions.Subscription
-	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_%s", azurePrefix, name))
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_%s", s.prefix, name))
 	if result != nil {
 		err := json.Unmarshal(result, &data)
 		if err != nil {
@@ -71,19 +118,266 @@ func (s *AzureService) getSubscriptionFromRepository(ctx context.Context, name s
 	}
 
 	if v != nil {
-		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_%s", azurePrefix, name), serializedData, s.Cache.TTL(time.Second))
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_%s", s.prefix, name), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+func (s *AzureService) ListSubscriptions(ctx context.Context) ([]*armsubscriptions.Subscription, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ListSubscriptions")
+	defer span.End()
+
+	v, err := s.Repository.ListSubscriptions(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ListResourcesSince
+// Recursos criados ou alterados a partir de since. O filtro é aplicado pelo backend e não usa o cache
+func (s *AzureService) ListResourcesSince(ctx context.Context, since time.Time) ([]*armresources.GenericResourceExpanded, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ListResourcesSince")
+	defer span.End()
+
+	var result []*armresources.GenericResourceExpanded
+	for page := range s.Repository.PageResources(ctxSpan, &entity.AzureResourceFilter{Since: since}) {
+		if page.Err != nil {
+			span.RecordError(page.Err)
+			return nil, page.Err
+		}
+		result = append(result, page.Resources...)
+	}
+
+	return result, ctxSpan.Err()
+}
+
+// Query
+// Consultas KQL customizadas não são armazenadas em cache
+func (s *AzureService) Query(ctx context.Context, query string) ([]map[string]interface{}, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.Query")
+	defer span.End()
+
+	v, err := s.Repository.Query(ctxSpan, query)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// ListResourceProperties
+// Consultas em lote não são armazenadas em cache
+func (s *AzureService) ListResourceProperties(ctx context.Context, ids []string) (map[string]interface{}, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ListResourceProperties")
+	defer span.End()
+
+	v, err := s.Repository.ListResourceProperties(ctxSpan, ids)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (s *AzureService) GetResourceByID(ctx context.Context, id string) (*entity.AzureResourceDetail, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.GetResourceByID")
+	defer span.End()
+
+	var data entity.AzureResourceDetail
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_resource_%s", s.prefix, strings.ToLower(id)))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.getResourceByIDFromRepository(ctxSpan, id)
+		return &data, nil
+	}
+
+	return s.getResourceByIDFromRepository(ctxSpan, id)
+}
+
+func (s *AzureService) getResourceByIDFromRepository(ctx context.Context, id string) (*entity.AzureResourceDetail, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.getResourceByIDFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.GetResourceByID(ctxSpan, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if v != nil {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_resource_%s", s.prefix, strings.ToLower(id)), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+func (s *AzureService) ListManagementGroups(ctx context.Context) ([]*entity.AzureManagementGroup, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ListManagementGroups")
+	defer span.End()
+
+	var data []*entity.AzureManagementGroup
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_management_groups", s.prefix))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.listManagementGroupsFromRepository(ctxSpan)
+		return data, nil
+	}
+
+	return s.listManagementGroupsFromRepository(ctxSpan)
+}
+
+func (s *AzureService) listManagementGroupsFromRepository(ctx context.Context) ([]*entity.AzureManagementGroup, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.listManagementGroupsFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.ListManagementGroups(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(v) > 0 {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_management_groups", s.prefix), serializedData, s.Cache.TTL(time.Second))
 	}
 
 	return v, nil
 }
 
+func (s *AzureService) ListDirectoryGroups(ctx context.Context) ([]*entity.AzureDirectoryGroup, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ListDirectoryGroups")
+	defer span.End()
+
+	var data []*entity.AzureDirectoryGroup
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_directory_groups", s.prefix))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.listDirectoryGroupsFromRepository(ctxSpan)
+		return data, nil
+	}
+
+	return s.listDirectoryGroupsFromRepository(ctxSpan)
+}
+
+func (s *AzureService) listDirectoryGroupsFromRepository(ctx context.Context) ([]*entity.AzureDirectoryGroup, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.listDirectoryGroupsFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.ListDirectoryGroups(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(v) > 0 {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_directory_groups", s.prefix), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+// directoryResources
+// Grupos e usuários do diretório normalizados. Um usuário em vários grupos é emitido uma única vez
+func (s *AzureService) directoryResources(ctx context.Context) ([]entity.CloudResource, error) {
+
+	groups, err := s.ListDirectoryGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result []entity.CloudResource
+	users := make(map[string]int)
+	for _, group := range groups {
+		parent := parseAzureDirectoryGroup(group)
+		result = append(result, parent)
+
+		for _, member := range group.Members {
+			i, ok := users[member.ID]
+			if !ok {
+				i = len(result)
+				users[member.ID] = i
+				result = append(result, parseAzureDirectoryUser(member))
+			}
+			result[i].MemberOf = append(result[i].MemberOf, entity.CloudReference{ID: parent.ID, Name: parent.Name})
+		}
+	}
+
+	return result, nil
+}
+
+// managementGroup
+// Grupo de gerenciamento pelo ID ou, com subscription preenchido, o grupo que contém a assinatura
+func (s *AzureService) managementGroup(ctx context.Context, id string, subscription string) (*entity.AzureManagementGroup, error) {
+
+	groups, err := s.ListManagementGroups(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, group := range groups {
+		if id != "" && strings.EqualFold(group.ID, id) {
+			return group, nil
+		}
+		if subscription != "" {
+			for _, sub := range group.Subscriptions {
+				if strings.EqualFold(sub, subscription) {
+					return group, nil
+				}
+			}
+		}
+	}
+
+	return nil, nil
+}
+
+// PageResources
+// Iterador paginado do repositório. As páginas não são armazenadas em cache
+func (s *AzureService) PageResources(ctx context.Context, filter *entity.AzureResourceFilter) <-chan entity.AzureResourcePage {
+	return s.Repository.PageResources(ctx, filter)
+}
+
 func (s *AzureService) ListResourcesByTag(ctx context.Context, tagKey, tagValue string) ([]*armresources.GenericResourceExpanded, error) {
 	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ListResourcesByTag")
 	defer span.End()
 
 	var data []*armresources.GenericResourceExpanded
 
-	queryPrefix := fmt.Sprintf("%s_key_%s_value_%s", azurePrefix, tagKey, tagValue)
+	queryPrefix := fmt.Sprintf("%s_key_%s_value_%s", s.prefix, tagKey, tagValue)
 	result, _ := s.Cache.Get(ctxSpan, queryPrefix)
 	if result != nil {
 		err := json.Unmarshal(result, &data)
@@ -114,7 +408,7 @@ func (s *AzureService) listResourcesByTagFromRepository(ctx context.Context, tag
 	}
 
 	if len(v) > 0 {
-		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_key_%s_value_%s", azurePrefix, tagKey, tagValue), serializedData, s.Cache.TTL(time.Second))
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_key_%s_value_%s", s.prefix, tagKey, tagValue), serializedData, s.Cache.TTL(time.Second))
 	}
 	return v, nil
 
@@ -125,7 +419,7 @@ func (s *AzureService) ListResourcesByResourceGroup(ctx context.Context, name st
 	defer span.End()
 
 	var data []*armresources.GenericResourceExpanded
-	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_%s", azurePrefix, name))
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_%s", s.prefix, name))
 	if result != nil {
 		err := json.Unmarshal(result, &data)
 		if err != nil {
@@ -156,7 +450,7 @@ func (s *AzureService) listResourcesByResourceGroupFromRepository(ctx context.Co
 	}
 
 	if len(v) > 0 {
-		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_%s", azurePrefix, name), serializedData, s.Cache.TTL(time.Second))
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_%s", s.prefix, name), serializedData, s.Cache.TTL(time.Second))
 	}
 
 	return v, nil
@@ -167,7 +461,7 @@ func (s *AzureService) ListResources(ctx context.Context) ([]*armresources.Gener
 	defer span.End()
 
 	var data []*armresources.GenericResourceExpanded
-	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_all_resources", azurePrefix))
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_all_resources", s.prefix))
 	if result != nil {
 		err := json.Unmarshal(result, &data)
 		if err != nil {
@@ -196,7 +490,7 @@ func (s *AzureService) listResourcesFromRepository(ctx context.Context) ([]*armr
 	}
 
 	if len(v) > 0 {
-		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_all_resources", azurePrefix), serializedData, s.Cache.TTL(time.Second))
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_all_resources", s.prefix), serializedData, s.Cache.TTL(time.Second))
 	}
 
 	return v, nil
@@ -207,7 +501,7 @@ func (s *AzureService) FilterResourcesByResourceGroup(ctx context.Context, name
 	defer span.End()
 
 	var data []*armresources.GenericResourceExpanded
-	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_rsg_%s", azurePrefix, name))
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_rsg_%s", s.prefix, name))
 	if result != nil {
 		err := json.Unmarshal(result, &data)
 		if err != nil {
@@ -237,7 +531,7 @@ func (s *AzureService) filterResourcesByResourceGroupFromRepository(ctx context.
 	}
 
 	if len(v) > 0 {
-		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_rsg_%s", azurePrefix, name), serializedData, s.Cache.TTL(time.Second))
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_rsg_%s", s.prefix, name), serializedData, s.Cache.TTL(time.Second))
 	}
 
 	return v, nil
@@ -250,9 +544,9 @@ func (s *AzureService) FilterResources(ctx context.Context, name ...string) ([]*
 	var data []*armresources.ResourceGroup
 	var result []byte
 	if len(name) > 0 && name[0] != "" {
-		result, _ = s.Cache.Get(ctxSpan, fmt.Sprintf("%s_azure_filter_rsg_%s", azurePrefix, name[0]))
+		result, _ = s.Cache.Get(ctxSpan, fmt.Sprintf("%s_azure_filter_rsg_%s", s.prefix, name[0]))
 	} else {
-		result, _ = s.Cache.Get(ctxSpan, fmt.Sprintf("%s_filter_tags", azurePrefix))
+		result, _ = s.Cache.Get(ctxSpan, fmt.Sprintf("%s_filter_tags", s.prefix))
 	}
 
 	if result != nil {
@@ -280,12 +574,670 @@ func (s *AzureService) filterResourcesFromRepository(ctx context.Context, name .
 	}
 	if len(v) > 0 {
 		if len(name) > 0 && name[0] != "" {
-			s.Cache.Set(ctx, fmt.Sprintf("%s_azure_filter_rsg_%s", azurePrefix, name[0]), serializedData, s.Cache.TTL(time.Second))
+			s.Cache.Set(ctx, fmt.Sprintf("%s_azure_filter_rsg_%s", s.prefix, name[0]), serializedData, s.Cache.TTL(time.Second))
 		} else {
-			s.Cache.Set(ctx, fmt.Sprintf("%s_filter_tags", azurePrefix), serializedData, s.Cache.TTL(time.Second))
+			s.Cache.Set(ctx, fmt.Sprintf("%s_filter_tags", s.prefix), serializedData, s.Cache.TTL(time.Second))
+		}
+	}
+
+	return v, nil
+
+}
+
+// GetResourceGroup
+// Grupo de recursos pelo ID da assinatura e pelo nome. Grupos com o mesmo nome em outras assinaturas não são confundidos
+func (s *AzureService) GetResourceGroup(ctx context.Context, subscriptionID, name string) (*armresources.ResourceGroup, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.GetResourceGroup")
+	defer span.End()
+
+	var data *armresources.ResourceGroup
+	result, _ := s.Cache.Get(ctxSpan, s.resourceGroupKey(subscriptionID, name))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.getResourceGroupFromRepository(ctxSpan, subscriptionID, name)
+		return data, nil
+	}
+
+	return s.getResourceGroupFromRepository(ctxSpan, subscriptionID, name)
+}
+
+func (s *AzureService) getResourceGroupFromRepository(ctx context.Context, subscriptionID, name string) (*armresources.ResourceGroup, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.getResourceGroupFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.GetResourceGroup(ctxSpan, subscriptionID, name)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if v == nil {
+		return nil, nil
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	s.Cache.Set(ctxSpan, s.resourceGroupKey(subscriptionID, name), serializedData, s.Cache.TTL(time.Second))
+
+	return v, nil
+}
+
+func (s *AzureService) resourceGroupKey(subscriptionID, name string) string {
+	return strings.ToLower(fmt.Sprintf("%s_resource_group_%s_%s", s.prefix, subscriptionID, name))
+}
+
+func (s *AzureService) Provider() entity.CloudProvider {
+	return entity.AZURE
+}
+
+func (s *AzureService) Account() string {
+	return s.Name
+}
+
+func (s *AzureService) ListCloudResources(ctx context.Context) ([]entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ListCloudResources")
+	defer span.End()
+
+	resources, err := s.ListResources(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return s.parseCloudResources(ctxSpan, newOwnershipIndex(), resources), nil
+}
+
+func (s *AzureService) FilterCloudResources(ctx context.Context, trigger *entity.Trigger) ([]entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.FilterCloudResources")
+	defer span.End()
+
+	var err error = nil
+	var resources []*armresources.GenericResourceExpanded
+
+	if trigger.TargetResource.ResourceName != "" {
+		resources, err = s.ListResourcesByResourceGroup(ctxSpan, trigger.TargetResource.ResourceName)
+	} else if trigger.TargetTags.Key != "" && trigger.TargetTags.Value != "" {
+		resources, err = s.ListResourcesByTag(ctxSpan, trigger.TargetTags.Key, trigger.TargetTags.Value)
+	} else {
+		resources, err = s.ListResources(ctxSpan)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return s.parseCloudResources(ctxSpan, newOwnershipIndex(), resources), nil
+}
+
+// StreamCloudResources
+// Converte cada página do repositório assim que ela chega, sem acumular a assinatura inteira em memória
+func (s *AzureService) StreamCloudResources(ctx context.Context, trigger *entity.Trigger) <-chan entity.CloudResourcePage {
+
+	filter := &entity.AzureResourceFilter{}
+	if trigger.TargetResource.ResourceName != "" {
+		filter.ResourceGroup = trigger.TargetResource.ResourceName
+	} else if trigger.TargetTags.Key != "" && trigger.TargetTags.Value != "" {
+		filter.TagKey = trigger.TargetTags.Key
+		filter.TagValue = trigger.TargetTags.Value
+	}
+
+	pages := make(chan entity.CloudResourcePage)
+	go func() {
+		defer close(pages)
+
+		ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.StreamCloudResources")
+		defer span.End()
+
+		owners := s.syncOwners()
+
+		// o diretório é emitido em toda sincronização para que os owners dos recursos sejam resolvidos
+		if s.Directory {
+			resources, err := s.directoryResources(ctxSpan)
+			if err != nil {
+				span.RecordError(err)
+				sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{Err: err})
+				return
+			}
+			if !sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{Resources: resources}) {
+				return
+			}
+		}
+
+		// a árvore completa é emitida apenas na sincronização sem filtro.
+		// Nas demais, os grupos usados aparecem pela cadeia de pais
+		if s.ManagementGroups && filter.IsEmpty() {
+			groups, err := s.ListManagementGroups(ctxSpan)
+			if err != nil {
+				span.RecordError(err)
+				sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{Err: err})
+				return
+			}
+
+			resources := make([]entity.CloudResource, 0, len(groups))
+			for _, group := range groups {
+				resource := parseAzureManagementGroup(group)
+				s.enrich(ctxSpan, owners, &resource)
+				resources = append(resources, resource)
+			}
+			if !sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{Resources: resources}) {
+				return
+			}
+		}
+
+		if s.Incremental.Enabled && filter.IsEmpty() {
+			if trigger.Full {
+				s.streamFull(ctxSpan, owners, pages)
+				return
+			}
+			s.streamIncremental(ctxSpan, owners, pages)
+			return
+		}
+
+		s.streamResources(ctxSpan, owners, pages, filter)
+	}()
+
+	return pages
+}
+
+// streamResources
+// Converte e entrega as páginas do repositório. Retorna false no primeiro erro ou cancelamento
+func (s *AzureService) streamResources(ctx context.Context, owners *ownershipIndex, pages chan<- entity.CloudResourcePage, filter *entity.AzureResourceFilter) bool {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.streamResources")
+	defer span.End()
+
+	for page := range s.Repository.PageResources(ctxSpan, filter) {
+		if page.Err != nil {
+			span.RecordError(page.Err)
+			sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{Err: page.Err})
+			return false
+		}
+
+		if !sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{Resources: s.parseCloudResources(ctxSpan, owners, page.Resources)}) {
+			return false
+		}
+	}
+	return ctxSpan.Err() == nil
+}
+
+// GetParent
+// Recurso -> grupo de recursos -> assinatura -> grupos de gerenciamento, quando habilitados
+func (s *AzureService) GetParent(ctx context.Context, resource *entity.CloudResource) (*entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.GetParent")
+	defer span.End()
+
+	if resource.ParentID == "" {
+		return nil, nil
+	}
+
+	switch resource.Level {
+	case entity.CloudResourceOrganization:
+		group, err := s.managementGroup(ctxSpan, resource.ParentID, "")
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if group == nil {
+			return nil, nil
+		}
+		parent := parseAzureManagementGroup(group)
+		s.enrich(ctxSpan, s.sharedOwners(), &parent)
+		return &parent, nil
+
+	case entity.CloudResourceAccount:
+		group, err := s.managementGroup(ctxSpan, "", resource.Account)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if group == nil {
+			return nil, nil
+		}
+		parent := parseAzureManagementGroup(group)
+		s.enrich(ctxSpan, s.sharedOwners(), &parent)
+		return &parent, nil
+
+	case entity.CloudResourceGroup:
+		sub, err := s.GetSubscription(ctxSpan, resource.Account, resource.Account)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if sub == nil {
+			return nil, nil
+		}
+		parent := parseAzureSubscription(sub)
+		parent.Annotations["azure_cloud"] = s.Cloud
+		s.enrich(ctxSpan, s.sharedOwners(), &parent)
+		if s.ManagementGroups {
+			if err := s.attachManagementGroup(ctxSpan, &parent); err != nil {
+				span.RecordError(err)
+				return nil, err
+			}
+		}
+		return &parent, nil
+	}
+
+	// recurso filho: o pai é outro recurso e não o grupo de recursos
+	if parentID, err := arm.ParseResourceID(resource.ParentID); err == nil && !strings.EqualFold(parentID.ResourceType.String(), arm.ResourceGroupResourceType.String()) {
+		detail, err := s.GetResourceByID(ctxSpan, resource.ParentID)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		if detail == nil || detail.Resource == nil {
+			return nil, nil
+		}
+		parent := parseAzureResource(azureExpandedResource(detail.Resource))
+		s.enrich(ctxSpan, s.sharedOwners(), &parent)
+		return &parent, nil
+	}
+
+	id, err := arm.ParseResourceID(resource.ID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	rsg, err := s.GetResourceGroup(ctxSpan, id.SubscriptionID, id.ResourceGroupName)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if rsg == nil {
+		return nil, nil
+	}
+
+	parent := parseAzureResourceGroup(rsg)
+	s.enrich(ctxSpan, s.sharedOwners(), &parent)
+	setSubscriptionName(&parent, s.subscriptionNames(ctxSpan))
+	return &parent, nil
+}
+
+// attachManagementGroup
+// Liga a assinatura ao grupo de gerenciamento que a contém
+func (s *AzureService) attachManagementGroup(ctx context.Context, sub *entity.CloudResource) error {
+
+	group, err := s.managementGroup(ctx, "", sub.Account)
+	if err != nil {
+		return err
+	}
+	if group == nil {
+		return nil
+	}
+
+	sub.ParentID = group.ID
+	sub.Annotations["management_group"] = group.Name
+	return nil
+}
+
+// enrich
+// Link do portal, owner pelas atribuições RBAC e, em seguida, criador pelo Activity Log
+func (s *AzureService) enrich(ctx context.Context, owners *ownershipIndex, resource *entity.CloudResource) {
+	if s.Portal != "" {
+		resource.Links = append(resource.Links, entity.CloudLink{
+			URL:   fmt.Sprintf("%s/#resource%s", s.Portal, resource.ID),
+			Title: "Azure Portal",
+		})
+	}
+	s.resolveOwner(ctx, owners, resource)
+	s.enrichCreatedBy(ctx, resource)
+}
+
+// parseCloudResources
+// Normaliza os recursos e identifica em cada um a assinatura de origem.
+// owners é compartilhado entre as páginas da mesma sincronização
+func (s *AzureService) parseCloudResources(ctx context.Context, owners *ownershipIndex, resources []*armresources.GenericResourceExpanded) []entity.CloudResource {
+
+	names := s.subscriptionNames(ctx)
+	properties := s.resourceProperties(ctx, resources)
+
+	result := make([]entity.CloudResource, 0, len(resources))
+	for _, r := range resources {
+		if r.ID == nil {
+			continue
+		}
+		resource := parseAzureResource(r)
+		resource.Annotations["azure_account"] = s.Name
+		resource.DependsOn = s.parseDependsOn(ctx, r, properties, names)
+		s.enrich(ctx, owners, &resource)
+		setSubscriptionName(&resource, names)
+		result = append(result, resource)
+
+		for _, child := range s.listChildResources(ctx, r) {
+			if child.ID == nil {
+				continue
+			}
+			resource := parseAzureResource(child)
+			resource.Annotations["azure_account"] = s.Name
+			resource.DependsOn = s.parseDependsOn(ctx, child, nil, names)
+			s.enrich(ctx, owners, &resource)
+			setSubscriptionName(&resource, names)
+			result = append(result, resource)
 		}
 	}
+	return result
+}
+
+// subscriptionNames
+// Nome de exibição das assinaturas da conta, indexado pelo ID
+func (s *AzureService) subscriptionNames(ctx context.Context) map[string]string {
+
+	names := make(map[string]string)
+	if subscriptions, err := s.ListSubscriptions(ctx); err == nil {
+		for _, sub := range subscriptions {
+			names[azureString(sub.SubscriptionID)] = azureString(sub.DisplayName)
+		}
+	}
+	return names
+}
+
+// setSubscriptionName
+// Anota o nome da assinatura e o usa como variável {subscription} do template de nome
+func setSubscriptionName(resource *entity.CloudResource, names map[string]string) {
+	if name, ok := names[resource.Account]; ok && name != "" {
+		resource.Annotations["subscription_name"] = name
+		resource.Scope["subscription"] = name
+	}
+}
+
+// listChildResources
+// Filhos configurados em child_resources para o tipo do recurso. Falhas são registradas no span e ignoradas
+func (s *AzureService) listChildResources(ctx context.Context, rsc *armresources.GenericResourceExpanded) []*armresources.GenericResourceExpanded {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.listChildResources")
+	defer span.End()
+
+	if rsc.Type == nil {
+		return nil
+	}
+
+	var result []*armresources.GenericResourceExpanded
+	for _, child := range s.Children {
+		if !strings.EqualFold(child.ParentType, *rsc.Type) {
+			continue
+		}
+
+		children, err := s.ListChildResources(ctxSpan, *rsc.ID, child.Path)
+		if err != nil {
+			span.RecordError(err)
+			continue
+		}
+		result = append(result, children...)
+	}
+	return result
+}
+
+// ListChildResources
+// Os filhos não são armazenados em cache
+func (s *AzureService) ListChildResources(ctx context.Context, parentID string, path string) ([]*armresources.GenericResourceExpanded, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ListChildResources")
+	defer span.End()
+
+	v, err := s.Repository.ListChildResources(ctxSpan, parentID, path)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
 
 	return v, nil
+}
+
+func parseAzureResource(rsc *armresources.GenericResourceExpanded) entity.CloudResource {
+
+	result := entity.CloudResource{
+		ID:          *rsc.ID,
+		Name:        azureString(rsc.Name),
+		Level:       entity.CloudResourceItem,
+		Provider:    "azure",
+		Location:    azureString(rsc.Location),
+		State:       azureString(rsc.ProvisioningState),
+		Tags:        azureTags(rsc.Tags),
+		Annotations: make(map[string]string),
+		Scope:       make(map[string]string),
+	}
+
+	// tipos aninhados mantêm o caminho completo. Exemplo servers/databases
+	if rsc.Type != nil {
+		resourceType := strings.Split(*rsc.Type, "/")
+		result.Family = strings.ToLower(resourceType[0])
+		result.Type = strings.ToLower(strings.Join(resourceType[1:], "/"))
+	}
+
+	if id, err := arm.ParseResourceID(*rsc.ID); err == nil {
+		result.Account = id.SubscriptionID
+		result.ParentID = fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", id.SubscriptionID, id.ResourceGroupName)
+		result.Annotations["subscription_id"] = id.SubscriptionID
+		result.Scope = azureScope(id.SubscriptionID, id.ResourceGroupName)
+
+		// recurso filho: nomeado pelos nomes do caminho e ligado ao recurso pai
+		if len(id.ResourceType.Types) > 1 && id.Parent != nil {
+			var names []string
+			for p := id; p != nil && len(p.ResourceType.Types) > 0 && p.ResourceType.Namespace == id.ResourceType.Namespace; p = p.Parent {
+				names = append([]string{p.Name}, names...)
+			}
+			result.Name = strings.Join(names, "-")
+			result.ParentID = id.Parent.String()
+			result.Annotations["resource_parent_id"] = id.Parent.String()
+		}
+	}
+
+	if rsc.CreatedTime != nil {
+		result.Annotations["resource_created_time"] = rsc.CreatedTime.UTC().Format(time.RFC3339)
+	}
+	if rsc.ChangedTime != nil {
+		result.Annotations["resource_changed_time"] = rsc.ChangedTime.UTC().Format(time.RFC3339)
+	}
+
+	return result
+}
+
+// azureExpandedResource
+// Converte o recurso retornado pelo GetByID no modelo da listagem
+func azureExpandedResource(rsc *armresources.GenericResource) *armresources.GenericResourceExpanded {
+
+	result := &armresources.GenericResourceExpanded{
+		ID:               rsc.ID,
+		Name:             rsc.Name,
+		Type:             rsc.Type,
+		Location:         rsc.Location,
+		Kind:             rsc.Kind,
+		ManagedBy:        rsc.ManagedBy,
+		Tags:             rsc.Tags,
+		SKU:              rsc.SKU,
+		Plan:             rsc.Plan,
+		Identity:         rsc.Identity,
+		ExtendedLocation: rsc.ExtendedLocation,
+		Properties:       rsc.Properties,
+	}
+
+	if properties, ok := rsc.Properties.(map[string]interface{}); ok {
+		if state, ok := properties["provisioningState"].(string); ok {
+			result.ProvisioningState = &state
+		}
+	}
+
+	return result
+}
+
+func parseAzureResourceGroup(rsg *armresources.ResourceGroup) entity.CloudResource {
+
+	result := entity.CloudResource{
+		ID:          azureString(rsg.ID),
+		Name:        azureString(rsg.Name),
+		Type:        "resourcegroups",
+		Family:      "microsoft.resources",
+		Level:       entity.CloudResourceGroup,
+		Provider:    "azure",
+		Location:    azureString(rsg.Location),
+		Tags:        azureTags(rsg.Tags),
+		Annotations: make(map[string]string),
+		Scope:       make(map[string]string),
+	}
+
+	if rsg.Properties != nil {
+		result.State = strings.ToLower(azureString(rsg.Properties.ProvisioningState))
+	}
+
+	if id, err := arm.ParseResourceID(result.ID); err == nil {
+		result.Account = id.SubscriptionID
+		result.ParentID = fmt.Sprintf("/subscriptions/%s", id.SubscriptionID)
+		result.Annotations["subscription_id"] = id.SubscriptionID
+		result.Scope = azureScope(id.SubscriptionID, id.ResourceGroupName)
+	}
 
+	return result
+}
+
+func parseAzureSubscription(sub *armsubscriptions.Subscription) entity.CloudResource {
+
+	result := entity.CloudResource{
+		ID:          azureString(sub.ID),
+		Name:        azureString(sub.DisplayName),
+		Type:        "subscription",
+		Level:       entity.CloudResourceAccount,
+		Provider:    "azure",
+		Account:     azureString(sub.SubscriptionID),
+		Tags:        azureTags(sub.Tags),
+		Annotations: make(map[string]string),
+	}
+
+	result.Scope = azureScope(result.Account, "")
+	if sub.DisplayName != nil {
+		result.Scope["subscription"] = *sub.DisplayName
+	}
+
+	result.Annotations["subscription_id"] = result.Account
+	if sub.State != nil {
+		result.Annotations["subscription_state"] = string(*sub.State)
+	}
+
+	if sub.SubscriptionPolicies != nil {
+		if sub.SubscriptionPolicies.QuotaID != nil {
+			result.Annotations["subscription_quota"] = *sub.SubscriptionPolicies.QuotaID
+		}
+		if sub.SubscriptionPolicies.SpendingLimit != nil {
+			result.Annotations["subscription_limit"] = string(*sub.SubscriptionPolicies.SpendingLimit)
+		}
+	}
+
+	return result
+}
+
+func parseAzureManagementGroup(group *entity.AzureManagementGroup) entity.CloudResource {
+
+	result := entity.CloudResource{
+		ID:          group.ID,
+		Name:        group.Name,
+		Type:        "managementgroup",
+		Family:      "microsoft.management",
+		Level:       entity.CloudResourceOrganization,
+		Provider:    "azure",
+		ParentID:    group.ParentID,
+		Tags:        make(map[string]string),
+		Annotations: make(map[string]string),
+	}
+
+	result.Annotations["management_group_id"] = group.ID
+	if group.DisplayName != "" {
+		result.Annotations["management_group_display_name"] = group.DisplayName
+	}
+	if group.TenantID != "" {
+		result.Annotations["tenant_id"] = group.TenantID
+	}
+
+	return result
+}
+
+func parseAzureDirectoryGroup(group *entity.AzureDirectoryGroup) entity.CloudResource {
+
+	name := group.MailNickname
+	if name == "" {
+		name = group.DisplayName
+	}
+
+	result := entity.CloudResource{
+		ID:          group.ID,
+		Name:        directoryName(name),
+		Type:        "team",
+		Level:       entity.CloudResourceDirectoryGroup,
+		Provider:    "azure",
+		DisplayName: group.DisplayName,
+		Email:       group.Mail,
+		Tags:        make(map[string]string),
+		Annotations: make(map[string]string),
+	}
+
+	result.Annotations["graph_group_id"] = group.ID
+	if group.MailNickname != "" {
+		result.Annotations["graph_group_mail_nickname"] = group.MailNickname
+	}
+	if group.Description != "" {
+		result.Annotations["graph_group_description"] = group.Description
+	}
+
+	return result
+}
+
+func parseAzureDirectoryUser(user *entity.AzureDirectoryUser) entity.CloudResource {
+
+	email := user.Mail
+	if email == "" {
+		email = user.UserPrincipalName
+	}
+
+	result := entity.CloudResource{
+		ID:          user.ID,
+		Name:        directoryName(user.UserPrincipalName),
+		Type:        "user",
+		Level:       entity.CloudResourceDirectoryUser,
+		Provider:    "azure",
+		DisplayName: user.DisplayName,
+		Email:       email,
+		Tags:        make(map[string]string),
+		Annotations: make(map[string]string),
+	}
+
+	result.Annotations["graph_user_id"] = user.ID
+	result.Annotations["graph_user_principal_name"] = user.UserPrincipalName
+
+	return result
+}
+
+// directoryName
+// Nome da entidade no formato do provider do Graph do Backstage: minúsculo, espaços por "-" e "@" por "_",
+// sanitizado para as regras de nome do Backstage
+func directoryName(name string) string {
+	return entity.SanitizeName(strings.NewReplacer(" ", "-", "@", "_").Replace(strings.ToLower(strings.TrimSpace(name))))
+}
+
+// azureScope
+// Variáveis do template de nome. {subscription} usa o ID até que o nome de exibição seja conhecido
+func azureScope(subscriptionID, resourceGroup string) map[string]string {
+	return map[string]string{
+		"subscription":    subscriptionID,
+		"subscription_id": subscriptionID,
+		"rg":              resourceGroup,
+	}
+}
+
+func azureTags(tags map[string]*string) map[string]string {
+	result := make(map[string]string)
+	for k, v := range tags {
+		if v != nil {
+			result[k] = *v
+		}
+	}
+	return result
+}
+
+func azureString(v *string) string {
+	if v == nil {
+		return ""
+	}
+	return *v
 }
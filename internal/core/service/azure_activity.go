@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// Anotações preenchidas a partir do Activity Log
+const (
+	createdByAnnotation      = "cloud-collector/created-by"
+	suggestedOwnerAnnotation = "cloud-collector/suggested-owner"
+)
+
+// ListActivityLog
+// Os eventos não são armazenados em cache. O enriquecimento usa activityCreators
+func (s *AzureService) ListActivityLog(ctx context.Context, subscriptionID string, since time.Time) ([]*entity.AzureActivityEvent, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ListActivityLog")
+	defer span.End()
+
+	v, err := s.Repository.ListActivityLog(ctxSpan, subscriptionID, since)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// activityCreators
+// Principal da primeira escrita de cada recurso da assinatura na janela de lookback, indexado pelo ID em minúsculas.
+// O resultado fica em cache por activity_log.cache_ttl e não é atualizado em segundo plano,
+// para que o Activity Log não seja consultado a cada sincronização
+func (s *AzureService) activityCreators(ctx context.Context, subscriptionID string) (map[string]string, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.activityCreators")
+	defer span.End()
+
+	key := fmt.Sprintf("%s_activity_creators_%s", s.prefix, subscriptionID)
+
+	var data map[string]string
+	result, _ := s.Cache.Get(ctxSpan, key)
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		return data, nil
+	}
+
+	events, err := s.ListActivityLog(ctxSpan, subscriptionID, time.Now().Add(-s.ActivityLog.GetLookback()))
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	data = make(map[string]string)
+	first := make(map[string]time.Time)
+	for _, event := range events {
+		if !event.IsWrite() || event.Caller == "" {
+			continue
+		}
+
+		id := strings.ToLower(event.ResourceID)
+		if at, ok := first[id]; ok && !event.EventTimestamp.Before(at) {
+			continue
+		}
+		first[id] = event.EventTimestamp
+		data[id] = event.Caller
+	}
+
+	serializedData, err := json.Marshal(data)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	s.Cache.Set(ctxSpan, key, serializedData, s.ActivityLog.GetCacheTTL())
+
+	return data, nil
+}
+
+// enrichCreatedBy
+// Anota o criador do recurso criado dentro da janela de lookback e o oferece como owner quando nenhuma outra regra definiu o owner.
+// Falhas são registradas no span e ignoradas
+func (s *AzureService) enrichCreatedBy(ctx context.Context, resource *entity.CloudResource) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.enrichCreatedBy")
+	defer span.End()
+
+	if !s.ActivityLog.CreatedBy || resource.Account == "" {
+		return
+	}
+
+	creators, err := s.activityCreators(ctxSpan, resource.Account)
+	if err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	// a primeira escrita da janela só é a criação quando o recurso foi criado dentro dela.
+	// Para recursos mais antigos ela indica apenas quem alterou por último
+	created, err := time.Parse(time.RFC3339, resource.Annotations["resource_created_time"])
+	if err != nil || created.Before(time.Now().Add(-s.ActivityLog.GetLookback())) {
+		return
+	}
+
+	caller, ok := creators[strings.ToLower(resource.ID)]
+	if !ok {
+		return
+	}
+
+	resource.Annotations[createdByAnnotation] = caller
+
+	// usuários viram referência à entidade User importada do Graph. Service principals são apenas anotados
+	if !strings.Contains(caller, "@") {
+		return
+	}
+	suggested := entity.EntityRef("User", entity.BackstageDefaultNamespace, directoryName(caller))
+	resource.Annotations[suggestedOwnerAnnotation] = suggested
+
+	if !s.hasOwnerTag(resource) && resource.Owner == "" {
+		resource.Owner = suggested
+		resource.Annotations[ownerRuleAnnotation] = "activitylog:created-by"
+	}
+}
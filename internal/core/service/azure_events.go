@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// ResourceChanged
+// Entidades afetadas pela escrita ou exclusão de um recurso.
+// Escritas são buscadas novamente no ARM, sem passar pelo cache; exclusões e recursos não encontrados viram tombstones
+// O owner usa o índice de atribuições da última sincronização
+func (s *AzureService) ResourceChanged(ctx context.Context, resourceID string, deleted bool) ([]entity.CloudResource, error) {
+	return s.resourceChanged(ctx, s.sharedOwners(), resourceID, deleted)
+}
+
+// resourceChanged
+// ResourceChanged com as atribuições de função compartilhadas pela sincronização incremental
+func (s *AzureService) resourceChanged(ctx context.Context, owners *ownershipIndex, resourceID string, deleted bool) ([]entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ResourceChanged")
+	defer span.End()
+
+	id, ok := s.trackedResourceID(resourceID)
+	if !ok {
+		return nil, nil
+	}
+
+	// a exclusão de um sub-recurso não rastreado altera o recurso pai
+	if deleted && strings.EqualFold(id, resourceID) {
+		return []entity.CloudResource{s.tombstone(ctxSpan, id)}, nil
+	}
+
+	detail, err := s.getResourceByIDFromRepository(ctxSpan, id)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	if detail == nil || detail.Resource == nil {
+		return []entity.CloudResource{s.tombstone(ctxSpan, id)}, nil
+	}
+
+	return s.parseCloudResources(ctxSpan, owners, []*armresources.GenericResourceExpanded{azureExpandedResource(detail.Resource)}), nil
+}
+
+// ManagesSubscription
+// Verifica se a assinatura é coletada por esta conta
+func (s *AzureService) ManagesSubscription(ctx context.Context, subscriptionID string) bool {
+
+	subscriptions, err := s.ListSubscriptions(ctx)
+	if err != nil {
+		return false
+	}
+	for _, sub := range subscriptions {
+		if strings.EqualFold(azureString(sub.SubscriptionID), subscriptionID) {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateEventSecret
+// Compara o segredo recebido pelo webhook com o configurado em event_grid.secret
+func (s *AzureService) ValidateEventSecret(secret string) bool {
+	if s.EventGrid.Secret == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(s.EventGrid.Secret), []byte(secret)) == 1
+}
@@ -0,0 +1,293 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// activityLogDelay atraso de ingestão do Activity Log. O cursor é recuado por este intervalo
+// para que eventos publicados depois da sincronização anterior não sejam perdidos
+const activityLogDelay = 15 * time.Minute
+
+// azureSyncCursor
+// Cursor da sincronização incremental de uma assinatura
+// LastSync início da última sincronização concluída
+// LastFullSync início da última sincronização completa
+type azureSyncCursor struct {
+	LastSync     time.Time `json:"last_sync"`
+	LastFullSync time.Time `json:"last_full_sync"`
+}
+
+func (s *AzureService) cursorKey(subscriptionID string) string {
+	return fmt.Sprintf("%s_sync_cursor_%s", s.prefix, subscriptionID)
+}
+
+// syncPlan
+// Separa as assinaturas que precisam de sincronização completa (sem cursor ou com o intervalo vencido)
+// das que podem ser sincronizadas a partir do cursor
+func (s *AzureService) syncPlan(ctx context.Context) ([]string, map[string]azureSyncCursor, error) {
+
+	subscriptions, err := s.ListSubscriptions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var full []string
+	partial := make(map[string]azureSyncCursor)
+	for _, sub := range subscriptions {
+		id := azureString(sub.SubscriptionID)
+		if id == "" {
+			continue
+		}
+
+		var cursor azureSyncCursor
+		result, _ := s.Cache.Get(ctx, s.cursorKey(id))
+		if result == nil || json.Unmarshal(result, &cursor) != nil || time.Since(cursor.LastFullSync) >= s.Incremental.GetFullResyncInterval() {
+			full = append(full, id)
+			continue
+		}
+		partial[id] = cursor
+	}
+
+	return full, partial, nil
+}
+
+// saveCursor
+// O cursor expira depois de dois intervalos de sincronização completa; sem ele a assinatura volta à sincronização completa
+func (s *AzureService) saveCursor(ctx context.Context, subscriptionID string, cursor azureSyncCursor) error {
+
+	serializedData, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	return s.Cache.Set(ctx, s.cursorKey(subscriptionID), serializedData, 2*s.Incremental.GetFullResyncInterval())
+}
+
+// streamIncremental
+// Lista por completo as assinaturas sem cursor válido e, nas demais, apenas os recursos alterados desde o cursor.
+// Os cursores são salvos pelo Checkpoint da última página, que o consumidor chama depois de publicar tudo.
+// Uma assinatura com falha ao converter as alterações mantém o cursor anterior
+func (s *AzureService) streamIncremental(ctx context.Context, owners *ownershipIndex, pages chan<- entity.CloudResourcePage) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.streamIncremental")
+	defer span.End()
+
+	start := time.Now()
+
+	full, partial, err := s.syncPlan(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{Err: err})
+		return
+	}
+
+	if len(full) > 0 && !s.streamResources(ctxSpan, owners, pages, &entity.AzureResourceFilter{Subscriptions: full}) {
+		return
+	}
+
+	ids := make([]string, 0, len(partial))
+	for id := range partial {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	cursors := make(map[string]azureSyncCursor, len(full)+len(ids))
+	for _, id := range full {
+		cursors[id] = azureSyncCursor{LastSync: start, LastFullSync: start}
+	}
+
+	for _, id := range ids {
+		resources, err := s.changedResources(ctxSpan, owners, id, partial[id].LastSync.Add(-activityLogDelay))
+		if err != nil {
+			span.RecordError(err)
+		} else {
+			cursors[id] = azureSyncCursor{LastSync: start, LastFullSync: partial[id].LastFullSync}
+		}
+		if len(resources) == 0 {
+			continue
+		}
+		if !sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{Resources: resources}) {
+			return
+		}
+	}
+
+	sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{
+		Checkpoint: func(ctx context.Context) error {
+			return s.saveCursors(ctx, cursors)
+		},
+	})
+}
+
+// streamFull
+// Sincronização completa pedida pelo trigger. A listagem sem filtro cobre todas as assinaturas,
+// então o Checkpoint da última página move LastSync e LastFullSync de cada uma
+func (s *AzureService) streamFull(ctx context.Context, owners *ownershipIndex, pages chan<- entity.CloudResourcePage) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.streamFull")
+	defer span.End()
+
+	start := time.Now()
+
+	subscriptions, err := s.ListSubscriptions(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{Err: err})
+		return
+	}
+
+	if !s.streamResources(ctxSpan, owners, pages, &entity.AzureResourceFilter{}) {
+		return
+	}
+
+	cursors := make(map[string]azureSyncCursor, len(subscriptions))
+	for _, sub := range subscriptions {
+		if id := azureString(sub.SubscriptionID); id != "" {
+			cursors[id] = azureSyncCursor{LastSync: start, LastFullSync: start}
+		}
+	}
+
+	sendCloudResourcePage(ctxSpan, pages, entity.CloudResourcePage{
+		Checkpoint: func(ctx context.Context) error {
+			return s.saveCursors(ctx, cursors)
+		},
+	})
+}
+
+// saveCursors
+// Salva os cursores das assinaturas sincronizadas
+func (s *AzureService) saveCursors(ctx context.Context, cursors map[string]azureSyncCursor) error {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.saveCursors")
+	defer span.End()
+
+	var errs []error
+	for id, cursor := range cursors {
+		if err := s.saveCursor(ctxSpan, id, cursor); err != nil {
+			span.RecordError(err)
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// changedResources
+// Recursos da assinatura escritos ou excluídos desde since, convertidos por ResourceChanged.
+// As alterações que não puderam ser convertidas são retornadas no erro, junto com as demais entidades
+func (s *AzureService) changedResources(ctx context.Context, owners *ownershipIndex, subscriptionID string, since time.Time) ([]entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.changedResources")
+	defer span.End()
+
+	events, err := s.ListActivityLog(ctxSpan, subscriptionID, since)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	type change struct {
+		resourceID string
+		at         time.Time
+		deleted    bool
+	}
+
+	// um único evento por entidade: o mais recente
+	latest := make(map[string]change)
+	for _, event := range events {
+		if !event.IsWrite() && !event.IsDelete() {
+			continue
+		}
+		id, ok := s.trackedResourceID(event.ResourceID)
+		if !ok {
+			continue
+		}
+
+		key := strings.ToLower(id)
+		if previous, ok := latest[key]; ok && previous.at.After(event.EventTimestamp) {
+			continue
+		}
+		latest[key] = change{resourceID: event.ResourceID, at: event.EventTimestamp, deleted: event.IsDelete()}
+	}
+
+	keys := make([]string, 0, len(latest))
+	for key := range latest {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var errs []error
+	var result []entity.CloudResource
+	for _, key := range keys {
+		c := latest[key]
+		resources, err := s.resourceChanged(ctxSpan, owners, c.resourceID, c.deleted)
+		if err != nil {
+			span.RecordError(err)
+			errs = append(errs, err)
+			continue
+		}
+		result = append(result, resources...)
+	}
+
+	return result, errors.Join(errs...)
+}
+
+// trackedResourceID
+// ID da entidade afetada pelo evento. Sub-recursos que não estão em child_resources são atribuídos ao recurso pai.
+// Eventos de assinatura e de grupos de recursos são ignorados
+func (s *AzureService) trackedResourceID(resourceID string) (string, bool) {
+
+	id, err := arm.ParseResourceID(resourceID)
+	if err != nil || id.ResourceGroupName == "" {
+		return "", false
+	}
+	if strings.EqualFold(id.ResourceType.String(), arm.ResourceGroupResourceType.String()) {
+		return "", false
+	}
+
+	for len(id.ResourceType.Types) > 1 && !s.isChildType(id.ResourceType.String()) && id.Parent != nil {
+		id = id.Parent
+	}
+	return id.String(), true
+}
+
+// isChildType
+// Tipo de recurso filho coletado pela configuração child_resources
+func (s *AzureService) isChildType(resourceType string) bool {
+	for _, child := range s.Children {
+		if strings.EqualFold(child.ChildType(), resourceType) {
+			return true
+		}
+	}
+	return false
+}
+
+// tombstone
+// Recurso excluído, identificado apenas pelo ID
+func (s *AzureService) tombstone(ctx context.Context, resourceID string) entity.CloudResource {
+
+	result := azureResourceFromID(resourceID)
+	result.Annotations["azure_account"] = s.Name
+	setSubscriptionName(&result, s.subscriptionNames(ctx))
+	result.State = "deleted"
+	result.Deleted = true
+	return result
+}
+
+// azureResourceFromID
+// Recurso normalizado apenas a partir do ID, com o mesmo nome, tipo e escopo da listagem
+func azureResourceFromID(resourceID string) entity.CloudResource {
+
+	rsc := &armresources.GenericResourceExpanded{ID: &resourceID}
+	if id, err := arm.ParseResourceID(resourceID); err == nil {
+		name := id.Name
+		resourceType := id.ResourceType.String()
+		rsc.Name = &name
+		rsc.Type = &resourceType
+	}
+
+	return parseAzureResource(rsc)
+}
@@ -0,0 +1,223 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// incrementalRepository
+// Repositório com apenas as assinaturas e o Activity Log. Os demais métodos não são usados pelos testes
+type incrementalRepository struct {
+	entity.AzureProviderInterface
+	subscriptions []string
+	activityErr   map[string]error
+	filters       []*entity.AzureResourceFilter
+}
+
+func (r *incrementalRepository) ListSubscriptions(context.Context) ([]*armsubscriptions.Subscription, error) {
+	result := make([]*armsubscriptions.Subscription, 0, len(r.subscriptions))
+	for i := range r.subscriptions {
+		result = append(result, &armsubscriptions.Subscription{SubscriptionID: &r.subscriptions[i]})
+	}
+	return result, nil
+}
+
+func (r *incrementalRepository) ListActivityLog(_ context.Context, subscriptionID string, _ time.Time) ([]*entity.AzureActivityEvent, error) {
+	return nil, r.activityErr[subscriptionID]
+}
+
+func (r *incrementalRepository) PageResources(_ context.Context, filter *entity.AzureResourceFilter) <-chan entity.AzureResourcePage {
+	r.filters = append(r.filters, filter)
+
+	pages := make(chan entity.AzureResourcePage, len(r.subscriptions))
+	for _, sub := range r.subscriptions {
+		id, kind := fmt.Sprintf("/subscriptions/%s/resourceGroups/rg/providers/Microsoft.Storage/storageAccounts/data", sub), "Microsoft.Storage/storageAccounts"
+		pages <- entity.AzureResourcePage{Resources: []*armresources.GenericResourceExpanded{{ID: &id, Type: &kind}}}
+	}
+	close(pages)
+	return pages
+}
+
+func newIncrementalService(repository *incrementalRepository, cache *memoryCache) *AzureService {
+	return &AzureService{
+		Repository:  repository,
+		Cache:       cache,
+		Tracer:      testTracer(),
+		Name:        "test",
+		Incremental: entity.AzureIncremental{Enabled: true, FullResyncInterval: 24 * time.Hour},
+		prefix:      azurePrefix,
+	}
+}
+
+func TestSyncPlan(t *testing.T) {
+
+	now := time.Now()
+	recent := azureSyncCursor{LastSync: now.Add(-time.Hour), LastFullSync: now.Add(-2 * time.Hour)}
+	expired := azureSyncCursor{LastSync: now.Add(-time.Hour), LastFullSync: now.Add(-25 * time.Hour)}
+
+	tests := []struct {
+		name        string
+		cursors     map[string]interface{}
+		wantFull    []string
+		wantPartial []string
+	}{
+		{name: "no cursors", wantFull: []string{"sub-a", "sub-b"}},
+		{name: "valid cursors", cursors: map[string]interface{}{"sub-a": recent, "sub-b": recent}, wantPartial: []string{"sub-a", "sub-b"}},
+		{name: "expired full sync", cursors: map[string]interface{}{"sub-a": recent, "sub-b": expired}, wantFull: []string{"sub-b"}, wantPartial: []string{"sub-a"}},
+		{name: "invalid cursor", cursors: map[string]interface{}{"sub-a": "invalid", "sub-b": recent}, wantFull: []string{"sub-a"}, wantPartial: []string{"sub-b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newMemoryCache()
+			s := newIncrementalService(&incrementalRepository{subscriptions: []string{"sub-a", "sub-b"}}, cache)
+			for id, cursor := range tt.cursors {
+				data, _ := json.Marshal(cursor)
+				cache.data[s.cursorKey(id)] = data
+			}
+
+			full, partial, err := s.syncPlan(context.Background())
+			if err != nil {
+				t.Fatalf("syncPlan() error = %v", err)
+			}
+
+			partialIDs := make([]string, 0, len(partial))
+			for id := range partial {
+				partialIDs = append(partialIDs, id)
+			}
+			sort.Strings(partialIDs)
+
+			if !equalStrings(full, tt.wantFull) || !equalStrings(partialIDs, tt.wantPartial) {
+				t.Errorf("syncPlan() = (%v, %v), want (%v, %v)", full, partialIDs, tt.wantFull, tt.wantPartial)
+			}
+		})
+	}
+}
+
+func TestStreamIncrementalCheckpoint(t *testing.T) {
+
+	now := time.Now()
+	previous := azureSyncCursor{LastSync: now.Add(-time.Hour).Truncate(time.Second), LastFullSync: now.Add(-2 * time.Hour).Truncate(time.Second)}
+
+	cache := newMemoryCache()
+	s := newIncrementalService(&incrementalRepository{
+		subscriptions: []string{"sub-ok", "sub-failed"},
+		activityErr:   map[string]error{"sub-failed": errors.New("activity log unavailable")},
+	}, cache)
+	for _, id := range []string{"sub-ok", "sub-failed"} {
+		data, _ := json.Marshal(previous)
+		cache.data[s.cursorKey(id)] = data
+	}
+
+	pages := make(chan entity.CloudResourcePage, 10)
+	s.streamIncremental(context.Background(), newOwnershipIndex(), pages)
+	close(pages)
+
+	var checkpoints []func(context.Context) error
+	for page := range pages {
+		if page.Err != nil {
+			t.Fatalf("streamIncremental() page error = %v", page.Err)
+		}
+		if page.Checkpoint != nil {
+			checkpoints = append(checkpoints, page.Checkpoint)
+		}
+	}
+	if len(checkpoints) != 1 {
+		t.Fatalf("streamIncremental() sent %d checkpoints, want 1", len(checkpoints))
+	}
+
+	cursor := func(id string) azureSyncCursor {
+		var result azureSyncCursor
+		if err := json.Unmarshal(cache.data[s.cursorKey(id)], &result); err != nil {
+			t.Fatal(err)
+		}
+		return result
+	}
+
+	// sem o checkpoint, nenhum cursor avança
+	if got := cursor("sub-ok"); !got.LastSync.Equal(previous.LastSync) {
+		t.Errorf("cursor of sub-ok saved before the checkpoint: %v", got.LastSync)
+	}
+
+	if err := checkpoints[0](context.Background()); err != nil {
+		t.Fatalf("checkpoint error = %v", err)
+	}
+
+	if got := cursor("sub-ok"); !got.LastSync.After(previous.LastSync) || !got.LastFullSync.Equal(previous.LastFullSync) {
+		t.Errorf("cursor of sub-ok = %+v, want LastSync after %v and LastFullSync %v", got, previous.LastSync, previous.LastFullSync)
+	}
+	if got := cursor("sub-failed"); !got.LastSync.Equal(previous.LastSync) {
+		t.Errorf("cursor of sub-failed = %+v, want the previous cursor %+v", got, previous)
+	}
+}
+
+func TestStreamFullCheckpoint(t *testing.T) {
+
+	now := time.Now()
+	previous := azureSyncCursor{LastSync: now.Add(-time.Hour).Truncate(time.Second), LastFullSync: now.Add(-2 * time.Hour).Truncate(time.Second)}
+
+	cache := newMemoryCache()
+	repository := &incrementalRepository{subscriptions: []string{"sub-a", "sub-b"}}
+	s := newIncrementalService(repository, cache)
+	data, _ := json.Marshal(previous)
+	cache.data[s.cursorKey("sub-a")] = data
+
+	var resources int
+	var checkpoints []func(context.Context) error
+	for page := range s.StreamCloudResources(context.Background(), &entity.Trigger{Full: true}) {
+		if page.Err != nil {
+			t.Fatalf("StreamCloudResources() page error = %v", page.Err)
+		}
+		resources += len(page.Resources)
+		if page.Checkpoint != nil {
+			checkpoints = append(checkpoints, page.Checkpoint)
+		}
+	}
+
+	if len(repository.filters) != 1 || !repository.filters[0].IsEmpty() {
+		t.Fatalf("StreamCloudResources() listed with %v, want a single unfiltered listing", repository.filters)
+	}
+	if resources != 2 || len(checkpoints) != 1 {
+		t.Fatalf("StreamCloudResources() sent %d resources and %d checkpoints, want 2 and 1", resources, len(checkpoints))
+	}
+	if err := checkpoints[0](context.Background()); err != nil {
+		t.Fatalf("checkpoint error = %v", err)
+	}
+
+	for _, id := range repository.subscriptions {
+		var cursor azureSyncCursor
+		if err := json.Unmarshal(cache.data[s.cursorKey(id)], &cursor); err != nil {
+			t.Fatalf("cursor of %s: %v", id, err)
+		}
+		if !cursor.LastFullSync.After(previous.LastFullSync) || !cursor.LastSync.Equal(cursor.LastFullSync) {
+			t.Errorf("cursor of %s = %+v, want LastSync and LastFullSync moved to the full sync", id, cursor)
+		}
+	}
+
+	// a próxima sincronização sem Full é incremental em todas as assinaturas
+	full, partial, err := s.syncPlan(context.Background())
+	if err != nil || len(full) != 0 || len(partial) != 2 {
+		t.Errorf("syncPlan() after a full sync = (%v, %v, %v), want every subscription partial", full, partial, err)
+	}
+}
+
+func equalStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
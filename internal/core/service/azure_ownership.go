@@ -0,0 +1,251 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// ownerRuleAnnotation regra que decidiu o owner da entidade
+const ownerRuleAnnotation = "cloud-collector/owner-rule"
+
+func (s *AzureService) ListRoleAssignments(ctx context.Context, subscriptionID string) ([]*entity.AzureRoleAssignment, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.ListRoleAssignments")
+	defer span.End()
+
+	var data []*entity.AzureRoleAssignment
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_role_assignments_%s", s.prefix, subscriptionID))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.listRoleAssignmentsFromRepository(ctxSpan, subscriptionID)
+		return data, nil
+	}
+
+	return s.listRoleAssignmentsFromRepository(ctxSpan, subscriptionID)
+}
+
+func (s *AzureService) listRoleAssignmentsFromRepository(ctx context.Context, subscriptionID string) ([]*entity.AzureRoleAssignment, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.listRoleAssignmentsFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.ListRoleAssignments(ctxSpan, subscriptionID)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(v) > 0 {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_role_assignments_%s", s.prefix, subscriptionID), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+// ownershipIndexTTL tempo em que o índice da última sincronização atende GetParent e os eventos
+const ownershipIndexTTL = 15 * time.Minute
+
+// ownershipIndex
+// Atribuições de função por assinatura e principals que podem ser owner.
+// Carregados uma única vez por sincronização e compartilhados por todos os recursos
+type ownershipIndex struct {
+	mu          sync.Mutex
+	created     time.Time
+	assignments map[string][]*entity.AzureRoleAssignment
+	principals  map[string]string
+}
+
+func newOwnershipIndex() *ownershipIndex {
+	return &ownershipIndex{created: time.Now(), assignments: make(map[string][]*entity.AzureRoleAssignment)}
+}
+
+// syncOwners
+// Índice novo para a sincronização, que passa a ser o índice compartilhado da conta
+func (s *AzureService) syncOwners() *ownershipIndex {
+
+	owners := newOwnershipIndex()
+
+	s.ownersMu.Lock()
+	s.owners = owners
+	s.ownersMu.Unlock()
+	return owners
+}
+
+// sharedOwners
+// Índice da última sincronização para as consultas fora dela (GetParent e eventos).
+// Depois de ownershipIndexTTL um novo índice é criado para que as atribuições sejam relidas
+func (s *AzureService) sharedOwners() *ownershipIndex {
+
+	s.ownersMu.Lock()
+	defer s.ownersMu.Unlock()
+
+	if s.owners == nil || time.Since(s.owners.created) > ownershipIndexTTL {
+		s.owners = newOwnershipIndex()
+	}
+	return s.owners
+}
+
+// roleAssignments
+// Atribuições da assinatura. Uma falha também é guardada para que a listagem não seja repetida a cada recurso
+func (s *AzureService) roleAssignments(ctx context.Context, owners *ownershipIndex, subscriptionID string) ([]*entity.AzureRoleAssignment, error) {
+
+	owners.mu.Lock()
+	defer owners.mu.Unlock()
+
+	if v, ok := owners.assignments[subscriptionID]; ok {
+		return v, nil
+	}
+
+	v, err := s.ListRoleAssignments(ctx, subscriptionID)
+	owners.assignments[subscriptionID] = v
+	return v, err
+}
+
+// ownerCandidates
+// Principals que podem ser owner, carregados na primeira consulta da sincronização
+func (s *AzureService) ownerCandidates(ctx context.Context, owners *ownershipIndex) (map[string]string, error) {
+
+	owners.mu.Lock()
+	defer owners.mu.Unlock()
+
+	if owners.principals != nil {
+		return owners.principals, nil
+	}
+
+	v, err := s.ownerPrincipals(ctx)
+	if err != nil {
+		owners.principals = map[string]string{}
+		return nil, err
+	}
+	owners.principals = v
+	return v, nil
+}
+
+// hasOwnerTag
+// O recurso já tem o owner em uma das tags configuradas em backstage.mapping.owner
+func (s *AzureService) hasOwnerTag(resource *entity.CloudResource) bool {
+	value, _ := s.OwnerMapping.Resolve(resource, nil)
+	return value != ""
+}
+
+// resolveOwner
+// Preenche o owner do recurso sem a tag de owner pelas atribuições de função.
+// Para cada escopo, do mais específico ao mais amplo, as funções são avaliadas na ordem configurada
+// e vence o primeiro principal mapeado para um grupo. Falhas são registradas no span e ignoradas
+func (s *AzureService) resolveOwner(ctx context.Context, owners *ownershipIndex, resource *entity.CloudResource) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.resolveOwner")
+	defer span.End()
+
+	if !s.Ownership.Enabled || resource.Account == "" {
+		return
+	}
+	if s.hasOwnerTag(resource) {
+		return
+	}
+
+	assignments, err := s.roleAssignments(ctxSpan, owners, resource.Account)
+	if err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	principals, err := s.ownerCandidates(ctxSpan, owners)
+	if err != nil {
+		span.RecordError(err)
+		return
+	}
+
+	for _, scope := range ownerScopes(resource) {
+		for _, role := range s.Ownership.GetRoles() {
+			for _, assignment := range assignments {
+				if !strings.EqualFold(assignment.Scope, scope.id) || assignment.RoleDefinitionID != role.RoleDefinitionID {
+					continue
+				}
+
+				owner, ok := principals[strings.ToLower(assignment.PrincipalID)]
+				if !ok {
+					continue
+				}
+
+				resource.Owner = owner
+				resource.Annotations[ownerRuleAnnotation] = fmt.Sprintf("rbac:%s:%s", scope.level, role.Name)
+				return
+			}
+		}
+	}
+}
+
+// ownerPrincipals
+// Object IDs que podem ser owner: os mapeados em ownership.principals e os grupos importados do Graph
+func (s *AzureService) ownerPrincipals(ctx context.Context) (map[string]string, error) {
+
+	result := make(map[string]string)
+
+	if s.Directory {
+		groups, err := s.ListDirectoryGroups(ctx)
+		if err != nil {
+			return nil, err
+		}
+		for _, group := range groups {
+			result[strings.ToLower(group.ID)] = entity.EntityRef("Group", entity.BackstageDefaultNamespace, parseAzureDirectoryGroup(group).Name)
+		}
+	}
+
+	for id, name := range s.Ownership.Principals {
+		result[strings.ToLower(id)] = entity.QualifyRef(name, "group", entity.BackstageDefaultNamespace)
+	}
+
+	return result, nil
+}
+
+type ownerScope struct {
+	id    string
+	level string
+}
+
+// ownerScopes
+// Escopos avaliados para o recurso: o próprio recurso, o recurso pai, o grupo de recursos e a assinatura
+func ownerScopes(resource *entity.CloudResource) []ownerScope {
+
+	subscription := ownerScope{id: fmt.Sprintf("/subscriptions/%s", resource.Account), level: "subscription"}
+
+	switch resource.Level {
+	case entity.CloudResourceAccount:
+		return []ownerScope{subscription}
+	case entity.CloudResourceGroup:
+		return []ownerScope{{id: resource.ID, level: "resourcegroup"}, subscription}
+	}
+
+	result := []ownerScope{{id: resource.ID, level: "resource"}}
+	id, err := arm.ParseResourceID(resource.ID)
+	if err != nil {
+		return append(result, subscription)
+	}
+
+	for parent := id.Parent; parent != nil && len(parent.ResourceType.Types) > 0 && parent.ResourceGroupName != ""; parent = parent.Parent {
+		if strings.EqualFold(parent.ResourceType.String(), arm.ResourceGroupResourceType.String()) {
+			break
+		}
+		result = append(result, ownerScope{id: parent.String(), level: "resource"})
+	}
+
+	if id.ResourceGroupName != "" {
+		result = append(result, ownerScope{id: fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", id.SubscriptionID, id.ResourceGroupName), level: "resourcegroup"})
+	}
+	return append(result, subscription)
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// azureRelationshipRules
+// Referências ARM conhecidas entre recursos. Regras do config são adicionadas a estas
+var azureRelationshipRules = []entity.AzureRelationshipRule{
+	{Type: "Microsoft.Web/sites", Path: "serverFarmId"},
+	{Type: "Microsoft.Compute/virtualMachines", Path: "networkProfile.networkInterfaces[].id"},
+	{Type: "Microsoft.Compute/virtualMachines", Path: "storageProfile.osDisk.managedDisk.id"},
+	{Type: "Microsoft.Compute/virtualMachines", Path: "storageProfile.dataDisks[].managedDisk.id"},
+	{Type: "Microsoft.Network/networkInterfaces", Path: "ipConfigurations[].properties.subnet.id", Parent: true},
+	{Type: "Microsoft.Network/networkInterfaces", Path: "networkSecurityGroup.id"},
+	{Type: "Microsoft.Network/privateEndpoints", Path: "subnet.id", Parent: true},
+	{Type: "Microsoft.Network/privateEndpoints", Path: "privateLinkServiceConnections[].properties.privateLinkServiceId"},
+	{Type: "Microsoft.Network/privateEndpoints", Path: "manualPrivateLinkServiceConnections[].properties.privateLinkServiceId"},
+}
+
+// relationshipRules
+// Regras aplicáveis ao tipo ARM informado
+func (s *AzureService) relationshipRules(resourceType string) []entity.AzureRelationshipRule {
+	var result []entity.AzureRelationshipRule
+	for _, rule := range s.Rules {
+		if strings.EqualFold(rule.Type, resourceType) {
+			result = append(result, rule)
+		}
+	}
+	return result
+}
+
+// resourceProperties
+// Propriedades dos recursos da página que têm regras de relacionamento e vieram sem properties (backend arm).
+// Busca todos em lote pelo Resource Graph em vez de um GET por recurso
+func (s *AzureService) resourceProperties(ctx context.Context, resources []*armresources.GenericResourceExpanded) map[string]interface{} {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.resourceProperties")
+	defer span.End()
+
+	var ids []string
+	for _, r := range resources {
+		if r.ID == nil || r.Type == nil || r.Properties != nil || len(s.relationshipRules(*r.Type)) == 0 {
+			continue
+		}
+		ids = append(ids, *r.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	result, err := s.Repository.ListResourceProperties(ctxSpan, ids)
+	if err != nil {
+		span.RecordError(err)
+		return nil
+	}
+	return result
+}
+
+// cachedResourceProperties
+// Propriedades de um recurso fora do lote: usa o cache sem disparar a atualização em background
+// e só consulta o ARM quando o recurso não está em cache
+func (s *AzureService) cachedResourceProperties(ctx context.Context, id string) interface{} {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.cachedResourceProperties")
+	defer span.End()
+
+	var detail *entity.AzureResourceDetail
+	if result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_resource_%s", s.prefix, strings.ToLower(id))); result != nil {
+		if err := json.Unmarshal(result, &detail); err != nil {
+			span.RecordError(err)
+			detail = nil
+		}
+	}
+	if detail == nil {
+		var err error
+		detail, err = s.getResourceByIDFromRepository(ctxSpan, id)
+		if err != nil {
+			span.RecordError(err)
+			return nil
+		}
+	}
+
+	if detail == nil || detail.Resource == nil {
+		return nil
+	}
+	return detail.Resource.Properties
+}
+
+// parseDependsOn
+// Aplica as regras do tipo sobre as propriedades do recurso.
+// O backend arm não retorna properties na listagem; nesse caso usa as propriedades buscadas em lote por resourceProperties
+func (s *AzureService) parseDependsOn(ctx context.Context, rsc *armresources.GenericResourceExpanded, batch map[string]interface{}, names map[string]string) []entity.CloudReference {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "AzureService.parseDependsOn")
+	defer span.End()
+
+	if rsc.Type == nil {
+		return nil
+	}
+
+	rules := s.relationshipRules(*rsc.Type)
+	if len(rules) == 0 {
+		return nil
+	}
+
+	properties := rsc.Properties
+	if properties == nil {
+		var ok bool
+		if properties, ok = batch[strings.ToLower(*rsc.ID)]; !ok {
+			properties = s.cachedResourceProperties(ctxSpan, *rsc.ID)
+		}
+	}
+	if properties == nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var result []entity.CloudReference
+	for _, rule := range rules {
+		for _, value := range propertyValues(properties, strings.Split(rule.Path, ".")) {
+			id, err := arm.ParseResourceID(value)
+			if err != nil {
+				continue
+			}
+			if rule.Parent && len(id.ResourceType.Types) > 1 {
+				id = id.Parent
+			}
+
+			key := strings.ToLower(id.String())
+			if seen[key] || strings.EqualFold(id.String(), *rsc.ID) {
+				continue
+			}
+			seen[key] = true
+
+			target := azureResourceFromID(id.String())
+			setSubscriptionName(&target, names)
+			result = append(result, entity.CloudReference{
+				ID:      target.ID,
+				Name:    target.Name,
+				Type:    target.Type,
+				Account: target.Account,
+				Scope:   target.Scope,
+			})
+		}
+	}
+	return result
+}
+
+// propertyValues
+// Percorre o caminho nas propriedades e retorna os valores string encontrados
+func propertyValues(value interface{}, path []string) []string {
+
+	if len(path) == 0 {
+		if v, ok := value.(string); ok && v != "" {
+			return []string{v}
+		}
+		return nil
+	}
+
+	object, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	key, list := strings.CutSuffix(path[0], "[]")
+	var next interface{}
+	for k, v := range object {
+		if strings.EqualFold(k, key) {
+			next = v
+			break
+		}
+	}
+
+	if !list {
+		return propertyValues(next, path[1:])
+	}
+
+	items, ok := next.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var result []string
+	for _, item := range items {
+		result = append(result, propertyValues(item, path[1:])...)
+	}
+	return result
+}
@@ -0,0 +1,107 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// parentRepository
+// Repositório com grupos de recursos de mesmo nome em assinaturas diferentes
+type parentRepository struct {
+	entity.AzureProviderInterface
+	groups map[string][]string
+}
+
+func (r *parentRepository) ListSubscriptions(context.Context) ([]*armsubscriptions.Subscription, error) {
+	var result []*armsubscriptions.Subscription
+	for id := range r.groups {
+		id, name := id, strings.ToUpper(id)
+		result = append(result, &armsubscriptions.Subscription{SubscriptionID: &id, DisplayName: &name})
+	}
+	return result, nil
+}
+
+func (r *parentRepository) GetResourceGroup(_ context.Context, subscriptionID, name string) (*armresources.ResourceGroup, error) {
+	groups, ok := r.groups[subscriptionID]
+	if !ok {
+		return nil, fmt.Errorf("subscription %s: %w", subscriptionID, entity.ErrSubscriptionNotCollected)
+	}
+	for _, group := range groups {
+		if strings.EqualFold(group, name) {
+			id := fmt.Sprintf("/subscriptions/%s/resourceGroups/%s", subscriptionID, group)
+			owner := "team-" + subscriptionID
+			return &armresources.ResourceGroup{ID: &id, Name: &group, Tags: map[string]*string{"owner": &owner}}, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestAzureServiceGetParentResourceGroup(t *testing.T) {
+
+	repository := &parentRepository{groups: map[string][]string{
+		"sub-a": {"rg-app"},
+		"sub-b": {"rg-app", "rg-data"},
+	}}
+
+	tests := []struct {
+		name      string
+		id        string
+		wantID    string
+		wantOwner string
+	}{
+		{name: "first subscription", id: "/subscriptions/sub-a/resourceGroups/rg-app/providers/Microsoft.Web/sites/app", wantID: "/subscriptions/sub-a/resourceGroups/rg-app", wantOwner: "team-sub-a"},
+		{name: "same group name in another subscription", id: "/subscriptions/sub-b/resourceGroups/rg-app/providers/Microsoft.Web/sites/app", wantID: "/subscriptions/sub-b/resourceGroups/rg-app", wantOwner: "team-sub-b"},
+		{name: "group only in another subscription", id: "/subscriptions/sub-a/resourceGroups/rg-data/providers/Microsoft.Sql/servers/db"},
+	}
+
+	// o cache é compartilhado entre os casos, como entre as sincronizações
+	s := &AzureService{Repository: repository, Cache: newMemoryCache(), Tracer: testTracer(), prefix: azurePrefix}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+
+			resource := parseAzureResource(&armresources.GenericResourceExpanded{ID: &tt.id})
+			parent, err := s.GetParent(context.Background(), &resource)
+			if err != nil {
+				t.Fatalf("GetParent() error = %v", err)
+			}
+
+			if tt.wantID == "" {
+				if parent != nil {
+					t.Errorf("GetParent() = %s, want no parent", parent.ID)
+				}
+				return
+			}
+			if parent == nil || parent.ID != tt.wantID || parent.Tags["owner"] != tt.wantOwner {
+				t.Errorf("GetParent() = %+v, want %s owned by %s", parent, tt.wantID, tt.wantOwner)
+			}
+		})
+	}
+}
+
+func TestAzureServiceSharedOwners(t *testing.T) {
+
+	s := &AzureService{}
+
+	first := s.sharedOwners()
+	if s.sharedOwners() != first {
+		t.Error("sharedOwners() created a new index before the TTL")
+	}
+
+	synced := s.syncOwners()
+	if synced == first || s.sharedOwners() != synced {
+		t.Error("sharedOwners() does not return the index of the last sync")
+	}
+
+	synced.created = time.Now().Add(-ownershipIndexTTL - time.Second)
+	if s.sharedOwners() == synced {
+		t.Error("sharedOwners() kept an index older than the TTL")
+	}
+}
@@ -6,10 +6,9 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
-	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armsubscriptions"
 	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
 	"github.com/synera-br/golang-cloud-collector/pkg/cache"
 	"github.com/synera-br/golang-cloud-collector/pkg/mq"
@@ -21,311 +20,605 @@ type BackstageServiceInterface interface {
 }
 
 type BackstageService struct {
-	Azure  AzureServiceInterface
-	Amqp   mq.AMQPServiceInterface
-	Cache  cache.CacheInterface
-	Tracer *otelpkg.OtelPkgInstrument
+	Providers *ProviderRegistry
+	Naming    entity.BackstageNaming
+	Mapping   entity.BackstageMapping
+	Profiles  map[string]*backstageProfile
+	Sinks     []entity.BackstageSink
+	Amqp      mq.AMQPServiceInterface
+	Cache     cache.CacheInterface
+	Tracer    *otelpkg.OtelPkgInstrument
+	names     map[string]entityNameClaim
+	claims    map[string]string
+	namesMu   sync.Mutex
+	catalog   map[string]map[string]entity.BackstageEntity
+	catalogMu sync.RWMutex
 }
 
 const backstagePrefix = "backstage"
 
-func NewBackstageService(azure AzureServiceInterface, mq mq.AMQPServiceInterface, cache cache.CacheInterface, otl *otelpkg.OtelPkgInstrument) BackstageServiceInterface {
+// deletedAnnotation marca a entidade de um recurso excluído no provedor
+const deletedAnnotation = "cloud-collector/deleted"
 
-	return &BackstageService{
-		Azure:  azure,
-		Amqp:   mq,
-		Cache:  cache,
-		Tracer: otl,
+// invalidOwnerAnnotation valor da tag de owner descartado por não gerar uma referência válida
+const invalidOwnerAnnotation = "cloud-collector/invalid-owner"
+
+// NewBackstageService
+// config configuração da geração das entidades. Nil usa os padrões.
+// Os templates dos perfis são carregados aqui, então erros de template impedem a inicialização
+func NewBackstageService(providers *ProviderRegistry, config *entity.BackstageConfig, mq mq.AMQPServiceInterface, cache cache.CacheInterface, otl *otelpkg.OtelPkgInstrument) (BackstageServiceInterface, error) {
+
+	if config == nil {
+		config = &entity.BackstageConfig{}
+	}
+
+	profiles, err := loadProfiles(config.Profiles)
+	if err != nil {
+		return nil, err
 	}
+
+	return &BackstageService{
+		Providers: providers,
+		Naming:    config.Naming,
+		Mapping:   config.Mapping,
+		Profiles:  profiles,
+		Sinks:     config.GetSinks(),
+		Amqp:      mq,
+		Cache:     cache,
+		Tracer:    otl,
+		names:     make(map[string]entityNameClaim),
+		claims:    make(map[string]string),
+		catalog:   make(map[string]map[string]entity.BackstageEntity),
+	}, nil
 }
 
-func (b *BackstageService) TriggerSyncProvider(ctx context.Context, trigger *entity.Trigger) ([]entity.KindReource, error) {
+func (b *BackstageService) TriggerSyncProvider(ctx context.Context, trigger *entity.Trigger) ([]entity.BackstageEntity, error) {
 	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.TriggerSyncProvider")
 	defer span.End()
 
-	var err error = nil
-	if trigger.Provider == "azure" {
-		return b.azureTriggerSyncProvider(ctxSpan, trigger)
-
-	} else if trigger.Provider == "aws" {
-
-	} else {
+	providers := b.Providers.Find(&entity.Filter{
+		Provider: trigger.Provider,
+		Account:  trigger.Account,
+	})
+	if len(providers) == 0 {
 		span.RecordError(errors.New("provider not found"))
 		return nil, errors.New("provider not found")
 	}
-	return nil, err
-}
-
-func (b *BackstageService) TriggerSyncProviderTemp(ctx context.Context, trigger *entity.Trigger) ([]entity.KindReource, error) {
-	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.TriggerSyncProvider")
-	defer span.End()
 
-	var err error = nil
-	var getItems []*armresources.GenericResourceExpanded
-	response := []entity.KindReource{}
+	if len(providers) == 1 {
+		return b.syncProvider(ctxSpan, providers[0], trigger)
+	}
 
-	parseItems := make(map[string][]*armresources.GenericResourceExpanded)
+	return b.syncProviders(ctxSpan, providers, trigger)
+}
 
-	//
-	if trigger.TargetResource.ResourceName != "" && trigger.TargetResource.ResourceType != "" {
+// syncProviders
+// Sincroniza os provedores e contas selecionados em paralelo.
+// Os resultados dos provedores que responderam são retornados junto com os erros dos demais
+func (b *BackstageService) syncProviders(ctx context.Context, providers []entity.CloudProviderInterface, trigger *entity.Trigger) ([]entity.BackstageEntity, error) {
+	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.syncProviders")
+	defer span.End()
 
-	} else if trigger.TargetTags.Key != "" && trigger.TargetTags.Value != "" {
+	results := make([][]entity.BackstageEntity, len(providers))
+	errs := make([]error, len(providers))
 
-	} else {
-		getItems, err = b.Azure.ListResources(ctxSpan)
-		if err != nil {
-			return response, err
-		}
+	var wg sync.WaitGroup
+	for i, provider := range providers {
+		wg.Add(1)
+		go func(i int, provider entity.CloudProviderInterface) {
+			defer wg.Done()
+			results[i], errs[i] = b.syncProvider(ctxSpan, provider, trigger)
+		}(i, provider)
 	}
+	wg.Wait()
 
-	for _, item := range getItems {
-		if item.Tags != nil && item.Tags["owner"] != nil {
-			tag := *item.Tags["owner"]
-			parseItems[tag] = append(parseItems[tag], item)
+	var response []entity.BackstageEntity
+	for i := range providers {
+		if errs[i] != nil {
+			span.RecordError(errs[i])
+			continue
 		}
+		response = append(response, results[i]...)
 	}
 
-	return nil, err
+	return response, errors.Join(errs...)
 }
 
-func (b *BackstageService) azureTriggerSyncProvider(ctx context.Context, trigger *entity.Trigger) ([]entity.KindReource, error) {
-	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.azureTriggerSyncProvider")
+// syncProvider
+// Consome os recursos página a página: cada página é convertida e publicada antes da próxima ser lida.
+// Os pais já resolvidos e as entidades já publicadas são compartilhados entre as páginas.
+// Uma falha na publicação encerra a sincronização e os checkpoints do provedor não são confirmados
+func (b *BackstageService) syncProvider(ctx context.Context, provider entity.CloudProviderInterface, trigger *entity.Trigger) ([]entity.BackstageEntity, error) {
+	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.syncProvider")
 	defer span.End()
 
-	var err error = nil
-	var resources []*armresources.GenericResourceExpanded
+	ctxStream, cancel := context.WithCancel(ctxSpan)
+	defer cancel()
+
+	state := newRelationshipState()
+
+	var checkpoints []func(ctx context.Context) error
+	var response []entity.BackstageEntity
+	for page := range provider.StreamCloudResources(ctxStream, trigger) {
+		if page.Err != nil {
+			span.RecordError(page.Err)
+			return nil, page.Err
+		}
+		if page.Checkpoint != nil {
+			checkpoints = append(checkpoints, page.Checkpoint)
+		}
+		if len(page.Resources) == 0 {
+			continue
+		}
 
-	if trigger.TargetResource.ResourceName != "" {
-		resources, err = b.Azure.ListResourcesByResourceGroup(ctxSpan, trigger.TargetResource.ResourceName)
+		kinds, err := b.parseRelationship(ctxStream, provider, page.Resources, state)
 		if err != nil {
-			span.RecordError(err)
 			return nil, err
 		}
-	} else if trigger.TargetTags.Key != "" && trigger.TargetTags.Value != "" {
-		resources, err = b.Azure.ListResourcesByTag(ctxSpan, trigger.TargetTags.Key, trigger.TargetTags.Value)
-		if err != nil {
+		kinds = b.validateEntities(ctxStream, kinds)
+		if len(kinds) == 0 {
+			continue
+		}
+
+		if err := b.publishResourcesToAMQP(ctxStream, kinds); err != nil {
 			span.RecordError(err)
 			return nil, err
 		}
-	} else {
-		resources, err = b.Azure.ListResources(ctxSpan)
-		if err != nil {
+		b.releaseNames(ctxStream, kinds)
+		response = append(response, kinds...)
+	}
+
+	if err := ctxSpan.Err(); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	// dependências reversas descobertas depois que o recurso alvo já foi publicado
+	var updated []entity.BackstageEntity
+	for i := range response {
+		if state.applyDependencyOf(&response[i]) {
+			updated = append(updated, response[i])
+		}
+	}
+	if len(updated) > 0 {
+		if err := b.publishResourcesToAMQP(ctxStream, updated); err != nil {
 			span.RecordError(err)
 			return nil, err
 		}
 	}
 
-	response, err := b.parseRelationship(ctxSpan, resources)
+	b.remember(provider, response, replacesCatalog(trigger))
+
+	for _, checkpoint := range checkpoints {
+		if err := checkpoint(ctxSpan); err != nil {
+			span.RecordError(err)
+		}
+	}
+
+	return response, nil
+}
+
+// PublishCloudResources
+// Converte e publica apenas as entidades dos recursos informados. Os pais são resolvidos
+// para preencher o dependsOn, mas não são publicados novamente
+func (b *BackstageService) PublishCloudResources(ctx context.Context, provider entity.CloudProviderInterface, resources []entity.CloudResource) ([]entity.BackstageEntity, error) {
+	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.PublishCloudResources")
+	defer span.End()
+
+	ids := make(map[string]bool)
+	for i := range resources {
+		ids[strings.ToLower(resources[i].ID)] = true
+	}
+
+	kinds, err := b.parseRelationship(ctxSpan, provider, resources, newRelationshipState())
 	if err != nil {
+		span.RecordError(err)
 		return nil, err
 	}
 
-	b.publishResourcesToAMQP(ctxSpan, response)
+	var response []entity.BackstageEntity
+	for i := range kinds {
+		if ids[strings.ToLower(kinds[i].Metadata.Annotations[resourceIDAnnotation])] {
+			response = append(response, kinds[i])
+		}
+	}
+	response = b.validateEntities(ctxSpan, response)
+	if len(response) == 0 {
+		return nil, nil
+	}
 
-	return response, err
+	if err := b.publishResourcesToAMQP(ctxSpan, response); err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	b.releaseNames(ctxSpan, response)
+	b.remember(provider, response, false)
+
+	return response, nil
 }
 
-func (b *BackstageService) parseResourceID(ctx context.Context, id string) map[string]string {
-	_, span := b.Tracer.Tracer.Start(ctx, "BackstageService.parseResourceID")
+// parseToTemplate
+// Converte o recurso na entidade do Backstage. parents são os pais do recurso, do mais próximo até a raiz,
+// usados pela herança das regras de mapping
+func (b *BackstageService) parseToTemplate(ctx context.Context, resource *entity.CloudResource, parents []*entity.CloudResource) *entity.BackstageEntity {
+	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.parseToTemplate")
 	defer span.End()
 
-	result := make(map[string]string)
-	if id == "" {
-		return result
+	result := entity.NewBackstageEntity("Resource", resource.Name)
+	result.Spec.Type = resource.Type
+
+	result.Source = &entity.BackstageSource{Resource: *resource}
+	for _, parent := range parents {
+		result.Source.Parents = append(result.Source.Parents, *parent)
 	}
 
-	// Remove leading slash if needed
-	if strings.HasPrefix(id, "/") {
-		id = strings.TrimPrefix(id, "/")
+	switch resource.Level {
+	case entity.CloudResourceOrganization:
+		// grupos de gerenciamento representam a organização da landing zone
+		result.Kind = "Domain"
+		result.Metadata.Title = resource.Annotations["management_group_display_name"]
+	case entity.CloudResourceDirectoryGroup:
+		result.Kind = "Group"
+		result.Spec.Profile = &entity.Profile{DisplayName: resource.DisplayName, Email: resource.Email}
+	case entity.CloudResourceDirectoryUser:
+		result.Kind = "User"
+		result.Spec.Profile = &entity.Profile{DisplayName: resource.DisplayName, Email: resource.Email}
+		for _, group := range resource.MemberOf {
+			result.Spec.MemberOf = append(result.Spec.MemberOf, entity.EntityRef("Group", entity.BackstageDefaultNamespace, group.Name))
+		}
+	case entity.CloudResourceAccount:
+		// assinaturas não possuem família/tipo ARM
+	default:
+		result.Metadata.Annotations["resource_family"] = resource.Family
+		result.Metadata.Annotations["resource_type"] = resource.Type
 	}
 
-	// Split the string by '/'
-	parts := strings.Split(id, "/")
+	if resource.ID != "" {
+		result.Metadata.Annotations[resourceIDAnnotation] = resource.ID
+	}
+	if resource.State != "" {
+		result.Metadata.Annotations["resource_state"] = resource.State
+	}
+	for k, v := range resource.Annotations {
+		result.Metadata.Annotations[k] = v
+	}
+	if resource.Deleted {
+		result.Metadata.Annotations[deletedAnnotation] = "true"
+	}
 
-	for i, part := range parts {
-		result[strings.ToLower(fmt.Sprintf("%d", i))] = strings.ToLower(part)
+	// tags fora do formato de label do Backstage não são copiadas
+	for k, v := range resource.Tags {
+		if entity.ValidLabel(k, v) {
+			result.Metadata.Labels[k] = v
+		}
 	}
 
-	return result
-}
+	for _, link := range resource.Links {
+		result.Metadata.Links = append(result.Metadata.Links, entity.BackstageLink{URL: link.URL, Title: link.Title})
+	}
 
-func (b *BackstageService) parseToTemplate(ctx context.Context, resource interface{}, resourceType string) *entity.KindReource {
-	_, span := b.Tracer.Tracer.Start(ctx, "BackstageService.parseToTemplate")
-	defer span.End()
+	// grupos e usuários do diretório não possuem tags nem owner
+	if resource.Level != entity.CloudResourceDirectoryGroup && resource.Level != entity.CloudResourceDirectoryUser {
+		b.applyMapping(&result, resource, parents)
+	}
 
-	result := entity.KindReource{}
-	result.Metadata.Labels = make(map[string]string)
-	result.Metadata.Annotations = make(map[string]string)
-
-	if resourceType == "subscriptions" {
-		rsc, ok := resource.(*armsubscriptions.Subscription)
-		if ok {
-			result.Metadata.Name = *rsc.DisplayName
-			result.Spec.Type = "subscription"
-			result.Metadata.Annotations["subscription_state"] = string(*rsc.State)
-			result.Metadata.Annotations["subscription_quota"] = string(*rsc.SubscriptionPolicies.QuotaID)
-			result.Metadata.Annotations["subscription_limit"] = string(*rsc.SubscriptionPolicies.SpendingLimit)
-
-			for k, v := range rsc.Tags {
-				if v != nil {
-					result.Metadata.Labels[k] = *v
-				}
-			}
-			owner, exists := rsc.Tags["owner"]
-			if !exists {
-				return nil
-			}
-			result.Spec.Owner = *owner
+	result.Metadata.Name = b.entityName(ctxSpan, result.Kind, result.Metadata.Namespace, resource)
 
-			system, exists := rsc.Tags["system"]
-			if !exists {
-				return nil
-			}
-			result.Spec.System = *system
+	return &result
+}
+
+// applyMapping
+// Preenche owner, system, lifecycle, namespace, descrição e título pelas regras de mapping.
+// O owner segue a precedência: tag do recurso, tag herdada, owner resolvido pelo provedor e, por fim, o default
+func (b *BackstageService) applyMapping(result *entity.BackstageEntity, resource *entity.CloudResource, parents []*entity.CloudResource) {
+
+	value := func(rule entity.BackstageMappingRule) string {
+		if v, _ := rule.Resolve(resource, parents); v != "" {
+			return v
 		}
+		return rule.Default
+	}
 
-	} else if resourceType == "resourcegroups" {
+	if namespace := entity.SanitizeNamespace(value(b.Mapping.Namespace)); namespace != "" {
+		result.Metadata.Namespace = namespace
+	}
 
-		rsg, isRSG := resource.(*armresources.ResourceGroup)
+	owner := b.Mapping.GetOwner()
+	if v, source := owner.Resolve(resource, parents); v != "" {
+		result.Spec.Owner = v
+		result.Metadata.Annotations[ownerRuleAnnotation] = source
+	} else if resource.Owner != "" {
+		result.Spec.Owner = resource.Owner
+	} else if owner.Default != "" {
+		result.Spec.Owner = owner.Default
+		result.Metadata.Annotations[ownerRuleAnnotation] = "default"
+	}
 
-		if isRSG {
-			result.Metadata.Name = *rsg.Name
-			result.Spec.Type = strings.ToLower(strings.Split(*rsg.Type, "/")[1])
-			result.Metadata.Annotations["resource_family"] = strings.ToLower(strings.Split(*rsg.Type, "/")[0])
-			result.Metadata.Annotations["resource_type"] = strings.ToLower(strings.Split(*rsg.Type, "/")[1])
-			result.Metadata.Annotations["resource_state"] = strings.ToLower(*rsg.Properties.ProvisioningState)
+	if system := value(b.Mapping.GetSystem()); system != "" {
+		result.Spec.System = entity.QualifyRef(system, "system", result.Metadata.Namespace)
+	}
+	result.Spec.Lifecycle = value(b.Mapping.Lifecycle)
 
-			for k, v := range rsg.Tags {
-				if v != nil {
-					result.Metadata.Labels[k] = *v
-				}
-			}
-			owner, exists := rsg.Tags["owner"]
-			if !exists {
-				return nil
-			}
-			result.Spec.Owner = *owner
+	if description := value(b.Mapping.Description); description != "" {
+		result.Metadata.Description = description
+	}
+	if title := value(b.Mapping.Title); title != "" {
+		result.Metadata.Title = title
+	}
+}
 
-			system, exists := rsg.Tags["system"]
-			if !exists {
-				return nil
-			}
-			result.Spec.System = *system
-
-		}
-	} else {
-		rsc, isRSC := resource.(*armresources.GenericResourceExpanded)
-		if isRSC {
-			result.Metadata.Name = *rsc.Name
-			result.Spec.Type = strings.ToLower(strings.Split(*rsc.Type, "/")[1])
-			result.Metadata.Annotations["resource_family"] = strings.ToLower(strings.Split(*rsc.Type, "/")[0])
-			result.Metadata.Annotations["resource_type"] = strings.ToLower(strings.Split(*rsc.Type, "/")[1])
-			if rsc.ProvisioningState != nil {
-				result.Metadata.Annotations["resource_state"] = *rsc.ProvisioningState
-			}
+// relationshipState
+// Pais já resolvidos via GetParent, entidades já emitidas, dependências reversas
+// e grupos do diretório conhecidos de uma sincronização
+type relationshipState struct {
+	parents    map[string]*entity.CloudResource
+	seen       map[string]bool
+	dependents map[string][]string
+	groups     map[string]string
+}
 
-			for k, v := range rsc.Tags {
-				if v != nil {
-					result.Metadata.Labels[k] = *v
-				}
-			}
-			owner, exists := rsc.Tags["owner"]
-			if exists {
-				// return nil
-				result.Spec.Owner = *owner
-			}
+func newRelationshipState() *relationshipState {
+	return &relationshipState{
+		parents:    make(map[string]*entity.CloudResource),
+		seen:       make(map[string]bool),
+		dependents: make(map[string][]string),
+		groups:     make(map[string]string),
+	}
+}
 
-			system, exists := rsc.Tags["system"]
-			if exists {
-				// return nil
-				result.Spec.System = *system
-			}
+// addGroup
+// Registra os nomes pelos quais o grupo do diretório pode aparecer na tag owner
+func (r *relationshipState) addGroup(resource *entity.CloudResource, item *entity.BackstageEntity) {
+	ref := item.Ref()
+	for _, alias := range []string{resource.ID, resource.Name, resource.DisplayName, resource.Email, resource.Annotations["graph_group_mail_nickname"]} {
+		if alias != "" {
+			r.groups[strings.ToLower(alias)] = ref
 		}
 	}
+}
 
-	result.Validate()
-	return &result
+// resolveOwner
+// Troca o owner vindo da tag pela referência ao grupo do diretório, quando conhecido.
+// Os demais owners são completados para kind:namespace/name, com group como kind padrão, e sanitizados.
+// Um owner sem nenhum caractere válido é trocado por fallback (mapping.owner.default) e o valor original fica anotado
+func (r *relationshipState) resolveOwner(item *entity.BackstageEntity, fallback string) {
+	if item.Spec.Owner == "" {
+		return
+	}
+	if ref, ok := r.groups[strings.ToLower(item.Spec.Owner)]; ok {
+		item.Spec.Owner = ref
+		return
+	}
+
+	owner := entity.SanitizeRef(item.Spec.Owner, "group", entity.BackstageDefaultNamespace)
+	if owner == "" {
+		item.Metadata.Annotations[invalidOwnerAnnotation] = item.Spec.Owner
+		item.Metadata.Annotations[ownerRuleAnnotation] = "default"
+		owner = entity.QualifyRef(fallback, "group", entity.BackstageDefaultNamespace)
+	}
+	item.Spec.Owner = owner
+}
+
+// entityKey
+// Identifica a entidade dentro de uma sincronização
+func entityKey(item *entity.BackstageEntity) string {
+	return fmt.Sprintf("%s/%s/%s", item.Metadata.Namespace, item.Metadata.Name, item.Spec.Type)
+}
+
+// add
+// Retorna false quando a entidade já foi emitida
+func (r *relationshipState) add(item *entity.BackstageEntity) bool {
+	key := entityKey(item)
+	if r.seen[key] {
+		return false
+	}
+	r.seen[key] = true
+	return true
+}
+
+// addDependent
+// Registra que dependent depende de target, para preencher o dependencyOf de target.
+// target é a referência completa kind:namespace/name, para não ligar entidades homônimas de outro kind ou namespace
+func (r *relationshipState) addDependent(target, dependent string) {
+	key := strings.ToLower(target)
+	if !contains(r.dependents[key], dependent) {
+		r.dependents[key] = append(r.dependents[key], dependent)
+	}
+}
+
+// applyDependencyOf
+// Preenche o dependencyOf da entidade e retorna true quando houve alteração
+func (r *relationshipState) applyDependencyOf(item *entity.BackstageEntity) bool {
+	changed := false
+	for _, dependent := range r.dependents[strings.ToLower(item.Ref())] {
+		if !contains(item.Spec.DependencyOf, dependent) {
+			item.Spec.DependencyOf = append(item.Spec.DependencyOf, dependent)
+			changed = true
+		}
+	}
+	return changed
+}
+
+func contains(slice []string, item string) bool {
+	for _, v := range slice {
+		if v == item {
+			return true
+		}
+	}
+	return false
 }
 
-func (b *BackstageService) parseRelationship(ctx context.Context, resources []*armresources.GenericResourceExpanded) ([]entity.KindReource, error) {
-	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.azureTriggerSyncProvider")
+// parseRelationship
+// Converte os recursos e percorre a cadeia de pais de cada um via GetParent,
+// criando as dependências recurso -> pai -> ... -> raiz
+func (b *BackstageService) parseRelationship(ctx context.Context, provider entity.CloudProviderInterface, resources []entity.CloudResource, state *relationshipState) ([]entity.BackstageEntity, error) {
+	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.parseRelationship")
 	defer span.End()
 
-	var response []entity.KindReource
+	var response []entity.BackstageEntity
+	emit := func(item *entity.BackstageEntity) {
+		state.resolveOwner(item, b.Mapping.GetOwner().Default)
+		if state.add(item) {
+			response = append(response, *item)
+		}
+	}
+
+	for i := range resources {
+		chain, err := b.parentChain(ctxSpan, provider, &resources[i], state)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
 
-	for _, r := range resources {
-		if r.ID != nil {
-			parseID := b.parseResourceID(ctxSpan, *r.ID)
-			resultRsg, err := b.Azure.FilterResources(ctxSpan, parseID["resourcegroups"])
-			if err != nil {
-				span.RecordError(err)
-				return nil, err
+		items := make([]*entity.BackstageEntity, len(chain))
+		for j := range chain {
+			items[j] = b.parseToTemplate(ctxSpan, chain[j], chain[j+1:])
+		}
+
+		current, child := chain[0], items[0]
+		if current.Level == entity.CloudResourceDirectoryGroup {
+			state.addGroup(current, child)
+		}
+
+		for _, ref := range current.DependsOn {
+			target := b.referenceName(ctxSpan, child.Metadata.Namespace, current.Provider, ref)
+			dependsOn := entity.EntityRef("Resource", child.Metadata.Namespace, target)
+			if !contains(child.Spec.DependsOn, dependsOn) {
+				child.Spec.DependsOn = append(child.Spec.DependsOn, dependsOn)
 			}
+			state.addDependent(dependsOn, child.Ref())
+		}
+
+		for j := 1; j < len(items); j++ {
+			attachParent(items[j-1], items[j])
+			emit(items[j-1])
+		}
+		emit(items[len(items)-1])
+	}
+
+	for i := range response {
+		state.applyDependencyOf(&response[i])
+	}
+	return response, nil
+}
+
+// parentChain
+// O recurso seguido dos pais resolvidos via GetParent, do mais próximo até a raiz
+func (b *BackstageService) parentChain(ctx context.Context, provider entity.CloudProviderInterface, resource *entity.CloudResource, state *relationshipState) ([]*entity.CloudResource, error) {
 
-			resultSubs, err := b.Azure.GetSubscription(ctxSpan, "", "")
+	chain := []*entity.CloudResource{resource}
+	for current := resource; current.ParentID != ""; {
+		parent, exists := state.parents[current.ParentID]
+		if !exists {
+			var err error
+			parent, err = provider.GetParent(ctx, current)
 			if err != nil {
-				span.RecordError(err)
 				return nil, err
 			}
-
-			dependsSubs := b.parseToTemplate(ctxSpan, resultSubs, "subscriptions")
-			if !b.contains(ctxSpan, response, *dependsSubs) {
-				response = append(response, *dependsSubs)
-			}
-			dependsGroup := b.parseToTemplate(ctxSpan, resultRsg[0], "resourcegroups")
-			dependsGroup.Spec.DependsOn = append(dependsGroup.Spec.DependsOn, fmt.Sprintf("resource:%s", dependsSubs.Metadata.Name))
-			if !b.contains(ctxSpan, response, *dependsGroup) {
-				response = append(response, *dependsGroup)
-			}
-			resource := b.parseToTemplate(ctxSpan, r, "resources")
-			if resource != nil {
-				resource.Spec.DependsOn = append(resource.Spec.DependsOn, fmt.Sprintf("resource:%s", dependsGroup.Metadata.Name))
-				if !b.contains(ctxSpan, response, *resource) {
-					response = append(response, *resource)
-				}
-			}
+			state.parents[current.ParentID] = parent
+		}
+		if parent == nil {
+			break
 		}
+
+		chain = append(chain, parent)
+		current = parent
+	}
+
+	return chain, nil
+}
+
+// attachParent
+// Liga a entidade ao pai: Domain dentro de Domain vira subdomainOf, os demais pais entram no dependsOn
+func attachParent(child, parent *entity.BackstageEntity) {
+
+	if child.Kind == "Domain" && parent.Kind == "Domain" {
+		child.Spec.SubdomainOf = parent.Ref()
+		return
+	}
+
+	dependsOn := parent.Ref()
+	if !contains(child.Spec.DependsOn, dependsOn) {
+		child.Spec.DependsOn = append(child.Spec.DependsOn, dependsOn)
 	}
-	return response, nil
 }
 
-func (b *BackstageService) contains(ctx context.Context, slice []entity.KindReource, item entity.KindReource) bool {
-	_, span := b.Tracer.Tracer.Start(ctx, "BackstageService.contains")
+// validateEntities
+// Retorna apenas as entidades aceitas pelo catálogo do Backstage. As rejeitadas são registradas no span e não são publicadas.
+// Entidades de recursos excluídos são publicadas apenas para remoção e precisam somente de nome e namespace válidos
+func (b *BackstageService) validateEntities(ctx context.Context, kinds []entity.BackstageEntity) []entity.BackstageEntity {
+	_, span := b.Tracer.Tracer.Start(ctx, "BackstageService.validateEntities")
 	defer span.End()
 
-	for _, v := range slice {
-		if (v.Metadata.Name == item.Metadata.Name) && (v.Metadata.Namespace == item.Metadata.Namespace) && (v.Spec.Type == item.Spec.Type) {
-			return true
+	response := make([]entity.BackstageEntity, 0, len(kinds))
+	for i := range kinds {
+		item := &kinds[i]
+
+		if item.Metadata.Annotations[deletedAnnotation] == "true" {
+			if !entity.ValidName(item.Metadata.Name) || !entity.ValidNamespace(item.Metadata.Namespace) {
+				span.RecordError(fmt.Errorf("%s: the deleted entity has no valid name", item.Ref()))
+				continue
+			}
+			response = append(response, *item)
+			continue
+		}
+
+		if err := item.Validate(); err != nil {
+			span.RecordError(err)
+			continue
 		}
+		response = append(response, *item)
 	}
-	return false
+
+	return response
 }
 
-func (b *BackstageService) publishResourcesToAMQP(ctx context.Context, data interface{}) error {
-	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.contains")
+// publishResourcesToAMQP
+// Publica as entidades em cada sink, no modelo padrão ou renderizadas pelo perfil do sink
+func (b *BackstageService) publishResourcesToAMQP(ctx context.Context, kinds []entity.BackstageEntity) error {
+	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.publishResourcesToAMQP")
 	defer span.End()
 
-	dataConvertToByte, err := json.Marshal(data)
-	if err != nil {
-		return err
-	}
+	var errs []error
+	for _, sink := range b.Sinks {
+		var data interface{} = kinds
+		if sink.Profile != "" {
+			documents, err := b.RenderProfile(ctxSpan, sink.Profile, kinds)
+			if err != nil {
+				span.RecordError(err)
+				errs = append(errs, err)
+				continue
+			}
+			data = documents
+		}
 
-	err = b.Amqp.Publish(ctxSpan, mq.DataAMQP{
-		ContentType: "application/json",
-		Exchange:    "collector",
-		RouteKey:    "backstage",
-		Queue:       "manifests",
-		Body:        dataConvertToByte,
-	})
+		dataConvertToByte, err := json.Marshal(data)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+
+		err = b.Amqp.Publish(ctxSpan, mq.DataAMQP{
+			ContentType: "application/json",
+			Exchange:    sink.Exchange,
+			RouteKey:    sink.RouteKey,
+			Queue:       sink.Queue,
+			Body:        dataConvertToByte,
+		})
+		if err != nil {
+			span.RecordError(err)
+			errs = append(errs, err)
+		}
+	}
 
-	return err
+	return errors.Join(errs...)
 }
 
-func (b *BackstageService) GetAllKinds(ctx context.Context, search entity.FilterKind) ([]entity.KindReource, error) {
+func (b *BackstageService) GetAllKinds(ctx context.Context, search entity.FilterKind) ([]entity.BackstageEntity, error) {
 	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.GetAllKinds")
 	defer span.End()
 
-	var data []entity.KindReource
+	var data []entity.BackstageEntity
 
 	queryPrefix := fmt.Sprintf("%s_get_all_kinds%s%s%s", backstagePrefix, search.Namespace, search.Kind, search.Name)
 
@@ -335,16 +628,12 @@ func (b *BackstageService) GetAllKinds(ctx context.Context, search entity.Filter
 		if err != nil {
 			return nil, err
 		}
-		go b.TriggerSyncProvider(ctxSpan, &entity.Trigger{
-			Provider: "azure",
-		})
+		go b.TriggerSyncProvider(ctxSpan, &entity.Trigger{Full: true})
 
 		return data, nil
 	}
 
-	objs, err := b.TriggerSyncProvider(ctxSpan, &entity.Trigger{
-		Provider: "azure",
-	})
+	objs, err := b.TriggerSyncProvider(ctxSpan, &entity.Trigger{Full: true})
 	if err != nil {
 		return nil, err
 	}
@@ -361,11 +650,35 @@ func (b *BackstageService) GetAllKinds(ctx context.Context, search entity.Filter
 	return filter, err
 }
 
-func (b *BackstageService) filterKinds(ctx context.Context, request []entity.KindReource, filter entity.FilterKind) ([]entity.KindReource, error) {
+// GetAllDocuments
+// Entidades publicadas pelas últimas sincronizações, renderizadas pelo perfil.
+// Não dispara uma sincronização: antes da primeira sincronização o resultado é vazio
+func (b *BackstageService) GetAllDocuments(ctx context.Context, search entity.FilterKind, profile string) ([]entity.BackstageDocument, error) {
+	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.GetAllDocuments")
+	defer span.End()
+
+	if _, ok := b.Profiles[profile]; !ok {
+		err := fmt.Errorf("the profile %s: %w", profile, entity.ErrProfileNotFound)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	objs := b.catalogEntities(ctxSpan)
+	if search.Name != "" || search.Namespace != "" || search.Kind != "" {
+		objs, _ = b.filterKinds(ctxSpan, objs, search)
+	}
+	if len(objs) == 0 {
+		return nil, nil
+	}
+
+	return b.RenderProfile(ctxSpan, profile, objs)
+}
+
+func (b *BackstageService) filterKinds(ctx context.Context, request []entity.BackstageEntity, filter entity.FilterKind) ([]entity.BackstageEntity, error) {
 	_, span := b.Tracer.Tracer.Start(ctx, "BackstageService.filterKinds")
 	defer span.End()
 
-	var response []entity.KindReource
+	var response []entity.BackstageEntity
 	var err error
 	if filter.Name == "" && filter.Kind == "" && filter.Namespace == "" {
 		return nil, errors.New("filter requires at least one non-empty field")
@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// catalogKey
+// Identifica o provedor e a conta no catálogo em memória
+func catalogKey(provider entity.CloudProviderInterface) string {
+	return fmt.Sprintf("%d/%s", provider.Provider(), provider.Account())
+}
+
+// remember
+// Registra no catálogo em memória as entidades publicadas do provedor, com o recurso de origem usado pelos perfis.
+// Uma sincronização completa e sem filtros substitui as entidades do provedor; as demais apenas atualizam
+// e removem as entidades de recursos excluídos
+func (b *BackstageService) remember(provider entity.CloudProviderInterface, kinds []entity.BackstageEntity, replace bool) {
+	b.catalogMu.Lock()
+	defer b.catalogMu.Unlock()
+
+	key := catalogKey(provider)
+	if replace || b.catalog[key] == nil {
+		b.catalog[key] = make(map[string]entity.BackstageEntity, len(kinds))
+	}
+
+	for i := range kinds {
+		if kinds[i].Metadata.Annotations[deletedAnnotation] == "true" {
+			delete(b.catalog[key], entityKey(&kinds[i]))
+			continue
+		}
+		b.catalog[key][entityKey(&kinds[i])] = kinds[i]
+	}
+}
+
+// catalogEntities
+// Entidades publicadas pelas últimas sincronizações, ordenadas pela referência
+func (b *BackstageService) catalogEntities(ctx context.Context) []entity.BackstageEntity {
+	_, span := b.Tracer.Tracer.Start(ctx, "BackstageService.catalogEntities")
+	defer span.End()
+
+	b.catalogMu.RLock()
+	defer b.catalogMu.RUnlock()
+
+	var response []entity.BackstageEntity
+	for _, kinds := range b.catalog {
+		for _, item := range kinds {
+			response = append(response, item)
+		}
+	}
+
+	sort.Slice(response, func(i, j int) bool {
+		return entityKey(&response[i]) < entityKey(&response[j])
+	})
+	return response
+}
+
+// replacesCatalog
+// Sincronização que lista todos os recursos do provedor
+func replacesCatalog(trigger *entity.Trigger) bool {
+	return trigger.Full && trigger.TargetResource == (entity.FilterResource{}) && trigger.TargetTags == (entity.FilterTag{})
+}
@@ -0,0 +1,187 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// resourceIDAnnotation ID original do recurso no provedor
+const resourceIDAnnotation = "cloud-collector/resource-id"
+
+// entityNameClaim
+// Nome gerado (antes do sufixo) e nome atribuído ao recurso
+type entityNameClaim struct {
+	base string
+	name string
+}
+
+// entityName
+// Nome da entidade pelo template do nível, sanitizado para as regras do Backstage.
+// Grupos e usuários do diretório mantêm o nome do provedor, já usado nas referências de owner e memberOf
+func (b *BackstageService) entityName(ctx context.Context, kind, namespace string, resource *entity.CloudResource) string {
+
+	if resource.Level == entity.CloudResourceDirectoryGroup || resource.Level == entity.CloudResourceDirectoryUser {
+		return resource.Name
+	}
+
+	vars := map[string]string{
+		"provider": resource.Provider,
+		"account":  resource.Account,
+		"level":    resource.Level,
+		"type":     resource.Type,
+		"location": resource.Location,
+		"name":     resource.Name,
+	}
+	for k, v := range resource.Scope {
+		vars[k] = v
+	}
+
+	return b.claimName(ctx, kind, namespace, entity.SanitizeName(b.Naming.Render(resource.Level, vars)), resource.ID)
+}
+
+// referenceName
+// Nome da entidade de um recurso referenciado pelas regras de relacionamento.
+// A localização do recurso referenciado não é conhecida e fica vazia no template
+func (b *BackstageService) referenceName(ctx context.Context, namespace, provider string, ref entity.CloudReference) string {
+	return b.entityName(ctx, "Resource", namespace, &entity.CloudResource{
+		ID:       ref.ID,
+		Name:     ref.Name,
+		Type:     ref.Type,
+		Level:    entity.CloudResourceItem,
+		Provider: provider,
+		Account:  ref.Account,
+		Scope:    ref.Scope,
+	})
+}
+
+func entityNameKey(kind, namespace, name string) string {
+	return fmt.Sprintf("%s_entity_name_%s_%s_%s", backstagePrefix, strings.ToLower(kind), namespace, name)
+}
+
+// claimName
+// O nome pertence ao primeiro recurso que o reivindicou. Os demais recebem o sufixo do hash do ID.
+// A reivindicação é gravada com SETNX, então duas réplicas não ficam com o mesmo nome, e expira em naming.claim_ttl.
+// O dono renova o TTL a cada sincronização; a reivindicação é liberada quando a entidade do recurso excluído é publicada.
+// Com o cache fora do ar, os nomes são disputados apenas entre os recursos deste processo
+func (b *BackstageService) claimName(ctx context.Context, kind, namespace, name, id string) string {
+	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.claimName")
+	defer span.End()
+
+	if id == "" {
+		return name
+	}
+	if name == "" {
+		return entity.SuffixName(name, id)
+	}
+
+	id = strings.ToLower(id)
+	memo := fmt.Sprintf("%s/%s/%s", strings.ToLower(kind), namespace, id)
+	key := entityNameKey(kind, namespace, name)
+	ttl := b.Naming.GetClaimTTL()
+
+	b.namesMu.Lock()
+	defer b.namesMu.Unlock()
+
+	// nomes com sufixo são mantidos. O dono confirma a reivindicação a cada sincronização,
+	// já que ela pode ter expirado e sido assumida por outra réplica
+	if claim, ok := b.names[memo]; ok && claim.base == name && claim.name != name {
+		return claim.name
+	}
+
+	result := name
+	if !b.ownsName(ctxSpan, key, id, ttl) {
+		result = entity.SuffixName(name, id)
+	}
+
+	b.names[memo] = entityNameClaim{base: name, name: result}
+	return result
+}
+
+// ownsName
+// Reivindica o nome com SETNX ou confirma que ele já pertence ao ID, renovando o TTL.
+// Quando a chave expira entre as duas consultas a reivindicação é tentada novamente.
+// O dono decidido pelo cache também é guardado em claims, usado no lugar do cache quando ele falha.
+// Chamado com namesMu
+func (b *BackstageService) ownsName(ctx context.Context, key, id string, ttl time.Duration) bool {
+	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.ownsName")
+	defer span.End()
+
+	for attempt := 0; attempt < 2; attempt++ {
+		claimed, err := b.Cache.SetNX(ctxSpan, key, []byte(id), ttl)
+		if err != nil {
+			span.RecordError(err)
+			return b.ownsLocalName(key, id)
+		}
+		if claimed {
+			b.claims[key] = id
+			return true
+		}
+
+		// chave expirada entre as consultas: a falha do cache aparece no próximo SETNX
+		owner, _ := b.Cache.Get(ctxSpan, key)
+		if owner == nil {
+			continue
+		}
+		b.claims[key] = string(owner)
+		if string(owner) != id {
+			return false
+		}
+		if _, err := b.Cache.Expire(ctxSpan, key, ttl); err != nil {
+			span.RecordError(err)
+		}
+		return true
+	}
+	return false
+}
+
+// ownsLocalName
+// Reivindicação em memória quando o cache não decide o dono: o primeiro ID do processo fica com o nome
+// e os demais recebem o sufixo, como no SETNX. Não protege contra outras réplicas
+func (b *BackstageService) ownsLocalName(key, id string) bool {
+
+	owner, ok := b.claims[key]
+	if !ok {
+		b.claims[key] = id
+		return true
+	}
+	return owner == id
+}
+
+// releaseNames
+// Libera os nomes das entidades de recursos excluídos
+func (b *BackstageService) releaseNames(ctx context.Context, kinds []entity.BackstageEntity) {
+	ctxSpan, span := b.Tracer.Tracer.Start(ctx, "BackstageService.releaseNames")
+	defer span.End()
+
+	b.namesMu.Lock()
+	defer b.namesMu.Unlock()
+
+	for i := range kinds {
+		item := &kinds[i]
+		id := strings.ToLower(item.Metadata.Annotations[resourceIDAnnotation])
+		if item.Metadata.Annotations[deletedAnnotation] != "true" || id == "" {
+			continue
+		}
+
+		memo := fmt.Sprintf("%s/%s/%s", strings.ToLower(item.Kind), item.Metadata.Namespace, id)
+		claim, ok := b.names[memo]
+		if !ok {
+			continue
+		}
+		delete(b.names, memo)
+
+		key := entityNameKey(item.Kind, item.Metadata.Namespace, claim.base)
+		if b.claims[key] == id {
+			delete(b.claims, key)
+		}
+		if owner, _ := b.Cache.Get(ctxSpan, key); string(owner) == id {
+			if _, err := b.Cache.Del(ctxSpan, key); err != nil {
+				span.RecordError(err)
+			}
+		}
+	}
+}
@@ -0,0 +1,225 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+func newNamingService(naming entity.BackstageNaming, cache *memoryCache) *BackstageService {
+	return &BackstageService{
+		Naming: naming,
+		Cache:  cache,
+		Tracer: testTracer(),
+		names:  make(map[string]entityNameClaim),
+		claims: make(map[string]string),
+	}
+}
+
+func TestEntityName(t *testing.T) {
+
+	resource := &entity.CloudResource{
+		ID:       "/subscriptions/1/resourceGroups/RG_App/providers/Microsoft.Storage/storageAccounts/Storage01",
+		Name:     "Storage01",
+		Type:     "Microsoft.Storage/storageAccounts",
+		Level:    entity.CloudResourceItem,
+		Provider: "azure",
+		Location: "eastus",
+		Scope:    map[string]string{"subscription": "Prod", "rg": "RG_App"},
+	}
+
+	tests := []struct {
+		name     string
+		naming   entity.BackstageNaming
+		resource *entity.CloudResource
+		want     string
+	}{
+		{name: "default template", resource: resource, want: "storage01"},
+		{name: "scope variables", naming: entity.BackstageNaming{Template: "{subscription}-{rg}-{name}"}, resource: resource, want: "prod-rg_app-storage01"},
+		{name: "empty variables", naming: entity.BackstageNaming{Template: "{account}-{name}"}, resource: resource, want: "storage01"},
+		{
+			name:     "directory groups keep the provider name",
+			naming:   entity.BackstageNaming{Template: "{subscription}-{name}"},
+			resource: &entity.CloudResource{ID: "group-1", Name: "platform", Level: entity.CloudResourceDirectoryGroup},
+			want:     "platform",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b := newNamingService(tt.naming, newMemoryCache())
+			if got := b.entityName(context.Background(), "Resource", "default", tt.resource); got != tt.want {
+				t.Errorf("entityName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaimName(t *testing.T) {
+
+	const first, second = "/subscriptions/1/storage01", "/subscriptions/2/storage01"
+
+	type claim struct {
+		kind string
+		name string
+		id   string
+		want string
+	}
+
+	tests := []struct {
+		name   string
+		claims []claim
+		setup  func(c *memoryCache)
+	}{
+		{
+			name:   "first resource keeps the name",
+			claims: []claim{{kind: "Resource", name: "storage01", id: first, want: "storage01"}},
+		},
+		{
+			name: "collision receives the suffix",
+			claims: []claim{
+				{kind: "Resource", name: "storage01", id: first, want: "storage01"},
+				{kind: "Resource", name: "storage01", id: second, want: entity.SuffixName("storage01", second)},
+			},
+		},
+		{
+			name: "same resource keeps its name",
+			claims: []claim{
+				{kind: "Resource", name: "storage01", id: first, want: "storage01"},
+				{kind: "Resource", name: "storage01", id: second, want: entity.SuffixName("storage01", second)},
+				{kind: "Resource", name: "storage01", id: first, want: "storage01"},
+				{kind: "Resource", name: "storage01", id: second, want: entity.SuffixName("storage01", second)},
+			},
+		},
+		{
+			name: "IDs ignore case",
+			claims: []claim{
+				{kind: "Resource", name: "storage01", id: first, want: "storage01"},
+				{kind: "Resource", name: "storage01", id: "/SUBSCRIPTIONS/1/STORAGE01", want: "storage01"},
+			},
+		},
+		{
+			name: "kinds do not collide",
+			claims: []claim{
+				{kind: "Resource", name: "prod", id: first, want: "prod"},
+				{kind: "System", name: "prod", id: second, want: "prod"},
+			},
+		},
+		{
+			name:   "name claimed by another replica",
+			setup:  func(c *memoryCache) { c.data[entityNameKey("Resource", "default", "storage01")] = []byte(second) },
+			claims: []claim{{kind: "Resource", name: "storage01", id: first, want: entity.SuffixName("storage01", first)}},
+		},
+		{
+			name:   "name claimed by the same resource in another replica",
+			setup:  func(c *memoryCache) { c.data[entityNameKey("Resource", "default", "storage01")] = []byte(first) },
+			claims: []claim{{kind: "Resource", name: "storage01", id: first, want: "storage01"}},
+		},
+		{
+			name:   "empty name",
+			claims: []claim{{kind: "Resource", name: "", id: first, want: entity.SuffixName("", first)}},
+		},
+		{
+			name:   "resource without ID",
+			claims: []claim{{kind: "Domain", name: "root", id: "", want: "root"}},
+		},
+		{
+			name:  "cache unavailable",
+			setup: func(c *memoryCache) { c.Err = errors.New("connection refused") },
+			claims: []claim{
+				{kind: "Resource", name: "storage01", id: first, want: "storage01"},
+				{kind: "Resource", name: "storage01", id: second, want: entity.SuffixName("storage01", second)},
+				{kind: "Resource", name: "storage01", id: first, want: "storage01"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cache := newMemoryCache()
+			if tt.setup != nil {
+				tt.setup(cache)
+			}
+			b := newNamingService(entity.BackstageNaming{}, cache)
+
+			for i, c := range tt.claims {
+				if got := b.claimName(context.Background(), c.kind, "default", c.name, c.id); got != c.want {
+					t.Errorf("claim %d: claimName(%q, %q) = %q, want %q", i, c.name, c.id, got, c.want)
+				}
+			}
+		})
+	}
+}
+
+func TestClaimNameAfterExpiration(t *testing.T) {
+
+	const first, second = "/subscriptions/1/storage01", "/subscriptions/2/storage01"
+	key := entityNameKey("Resource", "default", "storage01")
+
+	cache := newMemoryCache()
+	b := newNamingService(entity.BackstageNaming{}, cache)
+	ctx := context.Background()
+
+	if got := b.claimName(ctx, "Resource", "default", "storage01", first); got != "storage01" {
+		t.Fatalf("claimName() = %q, want storage01", got)
+	}
+
+	// a reivindicação expirou e outra réplica assumiu o nome
+	delete(cache.data, key)
+	cache.data[key] = []byte(second)
+
+	if got, want := b.claimName(ctx, "Resource", "default", "storage01", first), entity.SuffixName("storage01", first); got != want {
+		t.Errorf("claimName() = %q, want %q", got, want)
+	}
+}
+
+func TestReleaseNames(t *testing.T) {
+
+	const first, second = "/subscriptions/1/storage01", "/subscriptions/2/storage01"
+
+	cache := newMemoryCache()
+	b := newNamingService(entity.BackstageNaming{}, cache)
+	ctx := context.Background()
+
+	b.claimName(ctx, "Resource", "default", "storage01", first)
+
+	deleted := entity.NewBackstageEntity("Resource", "storage01")
+	deleted.Metadata.Annotations[resourceIDAnnotation] = first
+	deleted.Metadata.Annotations[deletedAnnotation] = "true"
+	b.releaseNames(ctx, []entity.BackstageEntity{deleted})
+
+	if got := b.claimName(ctx, "Resource", "default", "storage01", second); got != "storage01" {
+		t.Errorf("claimName() after release = %q, want storage01", got)
+	}
+}
+
+func TestClaimNameCacheFailureAfterClaim(t *testing.T) {
+
+	cache := newMemoryCache()
+	b := newNamingService(entity.BackstageNaming{}, cache)
+	ctx := context.Background()
+
+	first, second, third := "/subscriptions/a/storage01", "/subscriptions/b/storage01", "/subscriptions/c/storage01"
+	if got := b.claimName(ctx, "Resource", "default", "storage01", first); got != "storage01" {
+		t.Fatalf("claimName(first) = %q, want storage01", got)
+	}
+
+	// o cache cai no meio da sincronização: o dono reivindicado antes continua com o nome
+	cache.Err = errors.New("connection refused")
+
+	tests := []struct {
+		id   string
+		want string
+	}{
+		{id: first, want: "storage01"},
+		{id: third, want: entity.SuffixName("storage01", third)},
+		{id: second, want: entity.SuffixName("storage01", second)},
+	}
+	for _, tt := range tests {
+		if got := b.claimName(ctx, "Resource", "default", "storage01", tt.id); got != tt.want {
+			t.Errorf("claimName(%q) = %q, want %q", tt.id, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+	"gopkg.in/yaml.v3"
+)
+
+// backstageProfile
+// Perfil de renderização com o template já carregado
+type backstageProfile struct {
+	name     string
+	format   string
+	template *template.Template
+}
+
+// profileFuncs funções disponíveis nos templates dos perfis
+var profileFuncs = template.FuncMap{
+	"lower":    strings.ToLower,
+	"upper":    strings.ToUpper,
+	"join":     strings.Join,
+	"sanitize": entity.SanitizeName,
+	"ref":      entity.EntityRef,
+	"default": func(fallback, value string) string {
+		if value == "" {
+			return fallback
+		}
+		return value
+	},
+	"quote": strconv.Quote,
+	"toJson": func(value interface{}) (string, error) {
+		result, err := json.Marshal(value)
+		return string(result), err
+	},
+}
+
+// loadProfiles
+// Carrega os templates dos perfis. O template recebe o caminho do arquivo como nome,
+// então os erros de sintaxe e de execução apontam para arquivo:linha do template.
+// Erros de YAML ou JSON apontam para a linha da saída renderizada, ver renderedLine
+func loadProfiles(profiles []entity.BackstageProfile) (map[string]*backstageProfile, error) {
+
+	result := make(map[string]*backstageProfile, len(profiles))
+	for _, profile := range profiles {
+		content, err := os.ReadFile(profile.File)
+		if err != nil {
+			return nil, fmt.Errorf("the backstage profile %s: %w", profile.Name, err)
+		}
+
+		tpl, err := template.New(profile.File).Funcs(profileFuncs).Parse(string(content))
+		if err != nil {
+			return nil, fmt.Errorf("the backstage profile %s: %w", profile.Name, err)
+		}
+
+		result[profile.Name] = &backstageProfile{
+			name:     profile.Name,
+			format:   profile.GetFormat(),
+			template: tpl,
+		}
+	}
+
+	return result, nil
+}
+
+// render
+// Executa o template para a entidade e decodifica o YAML ou JSON gerado
+func (p *backstageProfile) render(item *entity.BackstageEntity) (entity.BackstageDocument, error) {
+
+	if item.Source == nil {
+		return nil, fmt.Errorf("profile %s: %s has no source resource", p.name, item.Ref())
+	}
+
+	parents := item.Source.Parents
+	if parents == nil {
+		parents = []entity.CloudResource{}
+	}
+
+	var output bytes.Buffer
+	err := p.template.Execute(&output, entity.BackstageTemplateData{
+		Resource: item.Source.Resource,
+		Parents:  parents,
+		Tags:     item.Source.Resource.Tags,
+		Entity:   *item,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %s: %w", p.name, item.Ref(), err)
+	}
+
+	// mapa genérico: o yaml.v3 decodificaria os mapas internos como BackstageDocument
+	var document map[string]interface{}
+	if p.format == entity.BackstageProfileJSON {
+		err = json.Unmarshal(output.Bytes(), &document)
+	} else {
+		err = yaml.Unmarshal(output.Bytes(), &document)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("profile %s: %s: the template %s rendered invalid %s: %w%s", p.name, item.Ref(), p.template.Name(), p.format, err, renderedLine(output.Bytes(), err))
+	}
+
+	for _, field := range []string{"apiVersion", "kind"} {
+		if value, _ := document[field].(string); value == "" {
+			return nil, fmt.Errorf("profile %s: %s: the template %s rendered no %s", p.name, item.Ref(), p.template.Name(), field)
+		}
+	}
+	metadata, _ := document["metadata"].(map[string]interface{})
+	if name, _ := metadata["name"].(string); name == "" {
+		return nil, fmt.Errorf("profile %s: %s: the template %s rendered no metadata.name", p.name, item.Ref(), p.template.Name())
+	}
+
+	return entity.BackstageDocument(document), nil
+}
+
+var yamlErrorLine = regexp.MustCompile(`line (\d+)`)
+
+// renderedLine
+// Linha da saída renderizada onde o YAML ou JSON ficou inválido. A numeração é a da saída,
+// não a do arquivo do template: ações e blocos do template mudam a contagem de linhas
+func renderedLine(output []byte, err error) string {
+
+	line := 0
+	var syntax *json.SyntaxError
+	if errors.As(err, &syntax) {
+		line = bytes.Count(output[:min(int(syntax.Offset), len(output))], []byte("\n")) + 1
+	} else if match := yamlErrorLine.FindStringSubmatch(err.Error()); match != nil {
+		line, _ = strconv.Atoi(match[1])
+	}
+
+	lines := strings.Split(string(output), "\n")
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return fmt.Sprintf(" (line %d of the rendered output: %q)", line, lines[line-1])
+}
+
+// RenderProfile
+// Converte as entidades pelo perfil. Entidades que o template não consegue gerar são registradas no span e descartadas
+func (b *BackstageService) RenderProfile(ctx context.Context, profile string, entities []entity.BackstageEntity) ([]entity.BackstageDocument, error) {
+	_, span := b.Tracer.Tracer.Start(ctx, "BackstageService.RenderProfile")
+	defer span.End()
+
+	p, ok := b.Profiles[profile]
+	if !ok {
+		err := fmt.Errorf("the profile %s: %w", profile, entity.ErrProfileNotFound)
+		span.RecordError(err)
+		return nil, err
+	}
+
+	var errs []error
+	response := make([]entity.BackstageDocument, 0, len(entities))
+	for i := range entities {
+		document, err := p.render(&entities[i])
+		if err != nil {
+			span.RecordError(err)
+			errs = append(errs, err)
+			continue
+		}
+		response = append(response, document)
+	}
+
+	// nenhuma entidade gerada indica um template quebrado e não dados ruins
+	if len(response) == 0 && len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return response, nil
+}
@@ -0,0 +1,215 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// writeProfile
+// Grava o template em um arquivo temporário e carrega o perfil
+func writeProfile(t *testing.T, format, content string) *backstageProfile {
+	t.Helper()
+
+	file := filepath.Join(t.TempDir(), "profile.tpl")
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	profiles, err := loadProfiles([]entity.BackstageProfile{{Name: "test", File: file, Format: format}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return profiles["test"]
+}
+
+func profileEntity() *entity.BackstageEntity {
+	item := entity.NewBackstageEntity("Resource", "storage01")
+	item.Spec.Type = "storage-account"
+	item.Spec.Owner = "group:default/platform"
+	item.Source = &entity.BackstageSource{
+		Resource: entity.CloudResource{
+			ID:   "/subscriptions/1/storage01",
+			Name: "Storage01",
+			Type: "Microsoft.Storage/storageAccounts",
+			Tags: map[string]string{"owner": "platform"},
+		},
+	}
+	return &item
+}
+
+func TestBackstageProfileRender(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		format   string
+		template string
+		want     map[string]string
+		wantErr  string
+	}{
+		{
+			name:   "yaml",
+			format: entity.BackstageProfileYAML,
+			template: `apiVersion: backstage.io/v1alpha1
+kind: Component
+metadata:
+  name: {{ .Entity.Metadata.Name }}
+  title: {{ .Resource.Name | quote }}
+spec:
+  owner: {{ .Tags.owner | default "unknown" }}
+`,
+			want: map[string]string{"kind": "Component", "metadata.name": "storage01", "metadata.title": "Storage01", "spec.owner": "platform"},
+		},
+		{
+			name:     "json",
+			format:   entity.BackstageProfileJSON,
+			template: `{"apiVersion": "backstage.io/v1alpha1", "kind": "Resource", "metadata": {"name": {{ .Entity.Metadata.Name | toJson }}}}`,
+			want:     map[string]string{"kind": "Resource", "metadata.name": "storage01"},
+		},
+		{
+			name:     "missing kind",
+			format:   entity.BackstageProfileYAML,
+			template: "apiVersion: backstage.io/v1alpha1\nmetadata:\n  name: x\n",
+			wantErr:  "rendered no kind",
+		},
+		{
+			name:     "missing name",
+			format:   entity.BackstageProfileYAML,
+			template: "apiVersion: backstage.io/v1alpha1\nkind: Resource\nmetadata: {}\n",
+			wantErr:  "rendered no metadata.name",
+		},
+		{
+			name:     "execution error",
+			format:   entity.BackstageProfileYAML,
+			template: "kind: {{ .Missing.Field }}\n",
+			wantErr:  "profile.tpl:1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			document, err := writeProfile(t, tt.format, tt.template).render(profileEntity())
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("render() error = %v, want it to contain %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("render() error = %v", err)
+			}
+
+			for path, want := range tt.want {
+				var value interface{} = map[string]interface{}(document)
+				for _, key := range strings.Split(path, ".") {
+					value = value.(map[string]interface{})[key]
+				}
+				if value != want {
+					t.Errorf("render() %s = %v, want %q", path, value, want)
+				}
+			}
+		})
+	}
+}
+
+func TestBackstageProfileRenderWithoutSource(t *testing.T) {
+
+	profile := writeProfile(t, entity.BackstageProfileYAML, "kind: Resource\n")
+
+	item := profileEntity()
+	item.Source = nil
+	if _, err := profile.render(item); err == nil {
+		t.Error("render() without source returned no error")
+	}
+}
+
+func TestRenderedLine(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		format   string
+		template string
+		want     string
+	}{
+		{
+			name:     "yaml",
+			format:   entity.BackstageProfileYAML,
+			template: "apiVersion: backstage.io/v1alpha1\nkind: Resource\nmetadata:\n  name: {{ .Resource.Name }}: broken\n",
+			want:     `line 4 of the rendered output: "  name: Storage01: broken"`,
+		},
+		{
+			name:     "yaml lines of the output, not of the template",
+			format:   entity.BackstageProfileYAML,
+			template: "{{- /* comentário\nem várias\nlinhas */ -}}\nkind: Resource\nmetadata:\n  name: {{ .Resource.Name }}: broken\n",
+			want:     `line 3 of the rendered output: "  name: Storage01: broken"`,
+		},
+		{
+			name:     "json",
+			format:   entity.BackstageProfileJSON,
+			template: "{\n  \"kind\": \"Resource\",\n  \"metadata\": {\"name\": {{ .Resource.Name }}}\n}\n",
+			want:     `line 3 of the rendered output: "  \"metadata\": {\"name\": Storage01}"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := writeProfile(t, tt.format, tt.template).render(profileEntity())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("render() error = %v, want it to contain %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderProfile(t *testing.T) {
+
+	valid := writeProfile(t, entity.BackstageProfileYAML, "apiVersion: v1\nkind: Resource\nmetadata:\n  name: {{ .Entity.Metadata.Name }}\n")
+	broken := writeProfile(t, entity.BackstageProfileYAML, "kind: Resource\n")
+
+	b := &BackstageService{
+		Profiles: map[string]*backstageProfile{"valid": valid, "broken": broken},
+		Tracer:   testTracer(),
+	}
+
+	withoutSource := *profileEntity()
+	withoutSource.Source = nil
+
+	tests := []struct {
+		name     string
+		profile  string
+		entities []entity.BackstageEntity
+		want     int
+		wantErr  error
+		anyErr   bool
+	}{
+		{name: "unknown profile", profile: "missing", wantErr: entity.ErrProfileNotFound},
+		{name: "no entities", profile: "valid", want: 0},
+		{name: "entities that fail are dropped", profile: "valid", entities: []entity.BackstageEntity{*profileEntity(), withoutSource}, want: 1},
+		{name: "broken template", profile: "broken", entities: []entity.BackstageEntity{*profileEntity()}, anyErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			documents, err := b.RenderProfile(context.Background(), tt.profile, tt.entities)
+			switch {
+			case tt.wantErr != nil:
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("RenderProfile() error = %v, want %v", err, tt.wantErr)
+				}
+			case tt.anyErr:
+				if err == nil {
+					t.Error("RenderProfile() returned no error")
+				}
+			default:
+				if err != nil || len(documents) != tt.want {
+					t.Errorf("RenderProfile() = %d documents, %v, want %d", len(documents), err, tt.want)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,417 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+	"github.com/synera-br/golang-cloud-collector/pkg/cache"
+	"github.com/synera-br/golang-cloud-collector/pkg/otelpkg"
+)
+
+type GCPServiceInterface interface {
+	entity.GCPProviderInterface
+	entity.CloudProviderInterface
+}
+
+type GCPService struct {
+	Repository entity.GCPProviderInterface
+	Cache      cache.CacheInterface
+	Tracer     *otelpkg.OtelPkgInstrument
+}
+
+const gcpPrefix = "gcp"
+
+func NewGCPService(provider *entity.GCPProviderInterface, cc *cache.CacheInterface, otl *otelpkg.OtelPkgInstrument) (GCPServiceInterface, error) {
+
+	return &GCPService{
+		Repository: *provider,
+		Cache:      *cc,
+		Tracer:     otl,
+	}, nil
+
+}
+
+func (s *GCPService) GetProject(ctx context.Context, name string) (*entity.GCPProject, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.GetProject")
+	defer span.End()
+
+	var data entity.GCPProject
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_%s", gcpPrefix, name))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.getProjectFromRepository(ctxSpan, name)
+		return &data, nil
+	}
+
+	return s.getProjectFromRepository(ctxSpan, name)
+}
+
+func (s *GCPService) getProjectFromRepository(ctx context.Context, name string) (*entity.GCPProject, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.getProjectFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.GetProject(ctxSpan, name)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if v != nil {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_%s", gcpPrefix, name), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+func (s *GCPService) GetFolder(ctx context.Context, name string) (*entity.GCPFolder, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.GetFolder")
+	defer span.End()
+
+	var data entity.GCPFolder
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_%s", gcpPrefix, name))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.getFolderFromRepository(ctxSpan, name)
+		return &data, nil
+	}
+
+	return s.getFolderFromRepository(ctxSpan, name)
+}
+
+func (s *GCPService) getFolderFromRepository(ctx context.Context, name string) (*entity.GCPFolder, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.getFolderFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.GetFolder(ctxSpan, name)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if v != nil {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_%s", gcpPrefix, name), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+func (s *GCPService) ListProjects(ctx context.Context) ([]*entity.GCPProject, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.ListProjects")
+	defer span.End()
+
+	v, err := s.Repository.ListProjects(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (s *GCPService) ListFolders(ctx context.Context) ([]*entity.GCPFolder, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.ListFolders")
+	defer span.End()
+
+	v, err := s.Repository.ListFolders(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return v, nil
+}
+
+func (s *GCPService) ListResourcesByLabel(ctx context.Context, labelKey, labelValue string) ([]*entity.GCPResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.ListResourcesByLabel")
+	defer span.End()
+
+	var data []*entity.GCPResource
+
+	queryPrefix := fmt.Sprintf("%s_key_%s_value_%s", gcpPrefix, labelKey, labelValue)
+	result, _ := s.Cache.Get(ctxSpan, queryPrefix)
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.listResourcesByLabelFromRepository(ctxSpan, labelKey, labelValue)
+		return data, nil
+	}
+	return s.listResourcesByLabelFromRepository(ctxSpan, labelKey, labelValue)
+}
+
+func (s *GCPService) listResourcesByLabelFromRepository(ctx context.Context, labelKey, labelValue string) ([]*entity.GCPResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.listResourcesByLabelFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.ListResourcesByLabel(ctxSpan, labelKey, labelValue)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(v) > 0 {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_key_%s_value_%s", gcpPrefix, labelKey, labelValue), serializedData, s.Cache.TTL(time.Second))
+	}
+	return v, nil
+}
+
+func (s *GCPService) ListResourcesByProject(ctx context.Context, project string) ([]*entity.GCPResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.ListResourcesByProject")
+	defer span.End()
+
+	var data []*entity.GCPResource
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_project_%s", gcpPrefix, project))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		go s.listResourcesByProjectFromRepository(ctxSpan, project)
+		return data, nil
+	}
+	return s.listResourcesByProjectFromRepository(ctxSpan, project)
+}
+
+func (s *GCPService) listResourcesByProjectFromRepository(ctx context.Context, project string) ([]*entity.GCPResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.listResourcesByProjectFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.ListResourcesByProject(ctxSpan, project)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(v) > 0 {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_project_%s", gcpPrefix, project), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+func (s *GCPService) ListResources(ctx context.Context) ([]*entity.GCPResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.ListResources")
+	defer span.End()
+
+	var data []*entity.GCPResource
+	result, _ := s.Cache.Get(ctxSpan, fmt.Sprintf("%s_all_resources", gcpPrefix))
+	if result != nil {
+		err := json.Unmarshal(result, &data)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+		go s.listResourcesFromRepository(ctxSpan)
+		return data, nil
+	}
+	return s.listResourcesFromRepository(ctxSpan)
+}
+
+func (s *GCPService) listResourcesFromRepository(ctx context.Context) ([]*entity.GCPResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.listResourcesFromRepository")
+	defer span.End()
+
+	v, err := s.Repository.ListResources(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+	serializedData, err := json.Marshal(v)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	if len(v) > 0 {
+		s.Cache.Set(ctxSpan, fmt.Sprintf("%s_all_resources", gcpPrefix), serializedData, s.Cache.TTL(time.Second))
+	}
+
+	return v, nil
+}
+
+func (s *GCPService) Provider() entity.CloudProvider {
+	return entity.GCP
+}
+
+func (s *GCPService) Account() string {
+	return entity.DefaultAccount
+}
+
+func (s *GCPService) ListCloudResources(ctx context.Context) ([]entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.ListCloudResources")
+	defer span.End()
+
+	resources, err := s.ListResources(ctxSpan)
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return parseGCPCloudResources(resources), nil
+}
+
+func (s *GCPService) FilterCloudResources(ctx context.Context, trigger *entity.Trigger) ([]entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.FilterCloudResources")
+	defer span.End()
+
+	var err error = nil
+	var resources []*entity.GCPResource
+
+	if trigger.TargetResource.ResourceName != "" {
+		resources, err = s.ListResourcesByProject(ctxSpan, trigger.TargetResource.ResourceName)
+	} else if trigger.TargetTags.Key != "" && trigger.TargetTags.Value != "" {
+		resources, err = s.ListResourcesByLabel(ctxSpan, trigger.TargetTags.Key, trigger.TargetTags.Value)
+	} else {
+		resources, err = s.ListResources(ctxSpan)
+	}
+	if err != nil {
+		span.RecordError(err)
+		return nil, err
+	}
+
+	return parseGCPCloudResources(resources), nil
+}
+
+// StreamCloudResources
+// O repositório não pagina a coleta; o resultado do filtro é entregue em uma página
+func (s *GCPService) StreamCloudResources(ctx context.Context, trigger *entity.Trigger) <-chan entity.CloudResourcePage {
+	return streamCloudResources(ctx, func(ctx context.Context) ([]entity.CloudResource, error) {
+		return s.FilterCloudResources(ctx, trigger)
+	})
+}
+
+// GetParent
+// Recurso -> projeto -> pastas até a organização
+func (s *GCPService) GetParent(ctx context.Context, resource *entity.CloudResource) (*entity.CloudResource, error) {
+	ctxSpan, span := s.Tracer.Tracer.Start(ctx, "GCPService.GetParent")
+	defer span.End()
+
+	if strings.HasPrefix(resource.ParentID, "projects/") {
+		project, err := s.GetProject(ctxSpan, resource.ParentID)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		parent := &entity.CloudResource{
+			ID:       project.Name,
+			Name:     project.ProjectID,
+			Type:     "project",
+			Level:    entity.CloudResourceAccount,
+			Provider: "gcp",
+			Account:  gcpAccount(project.Name),
+			State:    strings.ToLower(project.State),
+			Tags:     project.Labels,
+			Annotations: map[string]string{
+				"project_display_name": project.DisplayName,
+			},
+		}
+		if strings.HasPrefix(project.Parent, "folders/") {
+			parent.ParentID = project.Parent
+		}
+		return parent, nil
+	}
+
+	if strings.HasPrefix(resource.ParentID, "folders/") {
+		folder, err := s.GetFolder(ctxSpan, resource.ParentID)
+		if err != nil {
+			span.RecordError(err)
+			return nil, err
+		}
+
+		parent := &entity.CloudResource{
+			ID:       folder.Name,
+			Name:     strings.ToLower(folder.DisplayName),
+			Type:     "folder",
+			Level:    entity.CloudResourceGroup,
+			Provider: "gcp",
+			State:    strings.ToLower(folder.State),
+			Annotations: map[string]string{
+				"folder_name": folder.Name,
+			},
+		}
+		if strings.HasPrefix(folder.Parent, "folders/") {
+			parent.ParentID = folder.Parent
+		}
+		return parent, nil
+	}
+
+	return nil, nil
+}
+
+// gcpAccount
+// Conta dos recursos e do projeto pelo número do projeto. O Cloud Asset Inventory retorna
+// apenas projects/{número}, então o projeto usa o mesmo formato e não o ID
+func gcpAccount(project string) string {
+	return strings.TrimPrefix(project, "projects/")
+}
+
+func parseGCPCloudResources(resources []*entity.GCPResource) []entity.CloudResource {
+	result := make([]entity.CloudResource, 0, len(resources))
+	for _, r := range resources {
+		if r.Project == "" {
+			continue
+		}
+
+		assetType := strings.Split(r.AssetType, "/")
+		name := r.DisplayName
+		if name == "" {
+			name = r.Name[strings.LastIndex(r.Name, "/")+1:]
+		}
+
+		result = append(result, entity.CloudResource{
+			ID:       r.Name,
+			Name:     name,
+			Type:     strings.ToLower(assetType[len(assetType)-1]),
+			Family:   strings.ToLower(strings.TrimSuffix(assetType[0], ".googleapis.com")),
+			Level:    entity.CloudResourceItem,
+			Provider: "gcp",
+			Account:  gcpAccount(r.Project),
+			Location: r.Location,
+			State:    strings.ToLower(r.State),
+			ParentID: r.Project,
+			Tags:     r.Labels,
+		})
+	}
+	return result
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// ProviderRegistry
+// Provedores de nuvem configurados, indexados por entity.CloudProvider e pelo nome da conta
+type ProviderRegistry struct {
+	mu        sync.RWMutex
+	providers map[entity.CloudProvider]map[string]entity.CloudProviderInterface
+}
+
+func NewProviderRegistry() *ProviderRegistry {
+	return &ProviderRegistry{
+		providers: make(map[entity.CloudProvider]map[string]entity.CloudProviderInterface),
+	}
+}
+
+func (r *ProviderRegistry) Register(provider entity.CloudProviderInterface) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.providers[provider.Provider()] == nil {
+		r.providers[provider.Provider()] = make(map[string]entity.CloudProviderInterface)
+	}
+	r.providers[provider.Provider()][provider.Account()] = provider
+}
+
+func (r *ProviderRegistry) Get(provider entity.CloudProvider, account string) (entity.CloudProviderInterface, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	p, ok := r.providers[provider][account]
+	return p, ok
+}
+
+// Find
+// Filtra os provedores pelo nome do provedor e da conta. Campos vazios não filtram
+func (r *ProviderRegistry) Find(filter *entity.Filter) []entity.CloudProviderInterface {
+
+	var name entity.CloudProvider
+	var result []entity.CloudProviderInterface
+	for _, p := range r.List() {
+		if filter.Provider != "" && p.Provider() != name.GetProvider(strings.ToLower(filter.Provider)) {
+			continue
+		}
+		if filter.Account != "" && !strings.EqualFold(p.Account(), filter.Account) {
+			continue
+		}
+		result = append(result, p)
+	}
+	return result
+}
+
+// List
+// Retorna os provedores ordenados pelo valor do enum e pelo nome da conta
+func (r *ProviderRegistry) List() []entity.CloudProviderInterface {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	keys := make([]entity.CloudProvider, 0, len(r.providers))
+	for k := range r.providers {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] < keys[j] })
+
+	var result []entity.CloudProviderInterface
+	for _, k := range keys {
+		accounts := make([]string, 0, len(r.providers[k]))
+		for account := range r.providers[k] {
+			accounts = append(accounts, account)
+		}
+		sort.Strings(accounts)
+
+		for _, account := range accounts {
+			result = append(result, r.providers[k][account])
+		}
+	}
+	return result
+}
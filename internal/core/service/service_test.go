@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/synera-br/golang-cloud-collector/pkg/otelpkg"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// testTracer
+// Tracer sem exportador para os testes
+func testTracer() *otelpkg.OtelPkgInstrument {
+	return &otelpkg.OtelPkgInstrument{Tracer: noop.NewTracerProvider().Tracer("test")}
+}
+
+// memoryCache
+// Cache em memória com as mesmas regras do redis para SETNX e EXPIRE. Err simula a indisponibilidade do cache
+type memoryCache struct {
+	mu   sync.Mutex
+	data map[string][]byte
+	Err  error
+}
+
+func newMemoryCache() *memoryCache {
+	return &memoryCache{data: make(map[string][]byte)}
+}
+
+func (c *memoryCache) Set(_ context.Context, key string, val []byte, _ time.Duration) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Err != nil {
+		return c.Err
+	}
+	c.data[key] = val
+	return nil
+}
+
+func (c *memoryCache) SetNX(_ context.Context, key string, val []byte, _ time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Err != nil {
+		return false, c.Err
+	}
+	if _, ok := c.data[key]; ok {
+		return false, nil
+	}
+	c.data[key] = val
+	return true, nil
+}
+
+func (c *memoryCache) Expire(_ context.Context, key string, _ time.Duration) (bool, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Err != nil {
+		return false, c.Err
+	}
+	_, ok := c.data[key]
+	return ok, nil
+}
+
+func (c *memoryCache) Get(_ context.Context, key string) ([]byte, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.Err != nil {
+		return nil, c.Err
+	}
+	val, ok := c.data[key]
+	if !ok {
+		return nil, errors.New("redis: nil")
+	}
+	return val, nil
+}
+
+func (c *memoryCache) Exists(_ context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.data[key]; ok {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+func (c *memoryCache) Del(_ context.Context, key string) (int64, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.data[key]; !ok {
+		return 0, nil
+	}
+	delete(c.data, key)
+	return 1, nil
+}
+
+func (c *memoryCache) Ping(context.Context) (string, error) {
+	return "PONG", nil
+}
+
+func (c *memoryCache) TTL(ttl time.Duration) time.Duration {
+	return ttl
+}
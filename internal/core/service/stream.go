@@ -0,0 +1,33 @@
+package service
+
+import (
+	"context"
+
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+)
+
+// streamCloudResources
+// Entrega o resultado de list em uma única página, para os provedores sem iterador paginado
+func streamCloudResources(ctx context.Context, list func(ctx context.Context) ([]entity.CloudResource, error)) <-chan entity.CloudResourcePage {
+
+	pages := make(chan entity.CloudResourcePage, 1)
+	go func() {
+		defer close(pages)
+
+		resources, err := list(ctx)
+		sendCloudResourcePage(ctx, pages, entity.CloudResourcePage{Resources: resources, Err: err})
+	}()
+
+	return pages
+}
+
+// sendCloudResourcePage
+// Retorna false quando o contexto é cancelado antes do consumidor receber a página
+func sendCloudResourcePage(ctx context.Context, pages chan<- entity.CloudResourcePage, page entity.CloudResourcePage) bool {
+	select {
+	case pages <- page:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
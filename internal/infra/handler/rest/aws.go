@@ -0,0 +1,206 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+	"github.com/synera-br/golang-cloud-collector/internal/core/service"
+	"github.com/synera-br/golang-cloud-collector/pkg/otelpkg"
+)
+
+type AWSHandlerHttpInterface interface {
+	ListResources(c *gin.Context)
+	FindByRegion(c *gin.Context)
+	FindByTag(c *gin.Context)
+	GetAccount(c *gin.Context)
+}
+
+type AWSHandlerHttp struct {
+	Service service.AWSServiceInterface
+	Tracer  *otelpkg.OtelPkgInstrument
+}
+
+func NewAWSHandlerHttp(svc service.AWSServiceInterface, otl *otelpkg.OtelPkgInstrument, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) AWSHandlerHttpInterface {
+
+	aws := &AWSHandlerHttp{
+		Service: svc,
+		Tracer:  otl,
+	}
+
+	aws.handlers(routerGroup, middleware...)
+
+	return aws
+}
+
+func (c *AWSHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) {
+	middlewareList := make([]gin.HandlerFunc, len(middleware))
+	for i, mw := range middleware {
+		middlewareList[i] = mw
+	}
+
+	routerGroup.GET("/aws", append(middlewareList, c.ListResources)...)
+	routerGroup.GET("/aws/:region", append(middlewareList, c.FindByRegion)...)
+	routerGroup.GET("/aws/tags", append(middlewareList, c.FindByTag)...)
+	routerGroup.GET("/aws/account/:id", append(middlewareList, c.GetAccount)...)
+}
+
+// AWSListResources    godoc
+// @Summary     list all resources from account
+// @Tags        aws
+// @Accept       json
+// @Produce     json
+// @Description get all aws register
+// @Success     200 {object} []entity.AWSResource
+// @Failure     404 {object} string
+// @Failure     500 {object} string
+// @Router      /aws [get]
+func (obj *AWSHandlerHttp) ListResources(c *gin.Context) {
+	ctx, span := obj.Tracer.Tracer.Start(c.Request.Context(), "AWSHandlerHttp.ListResources")
+	defer span.End()
+
+	result, err := obj.Service.ListResources(ctx)
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	if len(result) == 0 {
+		c.JSON(http.StatusNotFound, "not found")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result)
+}
+
+// AWSFindByRegion    godoc
+// @Summary     list all resources from region
+// @Tags        aws
+// @Accept       json
+// @Produce     json
+// @Param       region path string true "region"
+// @Description get all aws register from region
+// @Success     200 {object} []entity.AWSResource
+// @Failure     404 {object} string
+// @Failure     500 {object} string
+// @Router      /aws/{region} [get]
+func (obj *AWSHandlerHttp) FindByRegion(c *gin.Context) {
+	ctx, span := obj.Tracer.Tracer.Start(c.Request.Context(), "AWSHandlerHttp.FindByRegion")
+	defer span.End()
+
+	region := c.Param("region")
+
+	if len(region) == 0 {
+		span.RecordError(errors.New("region not setted"))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "region not setted"})
+		return
+	}
+
+	result, err := obj.Service.ListResourcesByRegion(ctx, region)
+	if errors.Is(err, entity.ErrRegionNotFound) {
+		span.RecordError(err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error()})
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	if len(result) == 0 {
+		c.JSON(http.StatusNotFound, "not found")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result)
+}
+
+// AWSFindByTag    godoc
+// @Summary     list resources filter by tags
+// @Tags        aws
+// @Accept       json
+// @Produce     json
+// @Description find resources by tags
+// @Param key        query string false "Key filter"
+// @Param value        query string false "value filter"
+// @Success     200 {object} []entity.AWSResource
+// @Failure     404 {object} string
+// @Failure     500 {object} string
+// @Router      /aws/tags [get]
+func (obj *AWSHandlerHttp) FindByTag(c *gin.Context) {
+	ctx, span := obj.Tracer.Tracer.Start(c.Request.Context(), "AWSHandlerHttp.FindByTag")
+	defer span.End()
+
+	if len(c.Request.URL.Query()) != 2 {
+		span.RecordError(errors.New("key and value of tags not setted"))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "key and value of tags not setted"})
+		return
+	}
+
+	result, err := obj.Service.ListResourcesByTag(ctx, c.Request.URL.Query().Get("key"), c.Request.URL.Query().Get("value"))
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	if len(result) == 0 {
+		c.JSON(http.StatusNotFound, "not found")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result)
+}
+
+// AWSGetAccount    godoc
+// @Summary     get account information
+// @Tags        aws
+// @Accept       json
+// @Produce     json
+// @Description get account information
+// @Param       id path string true "account id"
+// @Success     200 {object} entity.AWSAccount
+// @Failure     404 {object} string
+// @Failure     500 {object} string
+// @Router      /aws/account/{id} [get]
+func (obj *AWSHandlerHttp) GetAccount(c *gin.Context) {
+	ctx, span := obj.Tracer.Tracer.Start(c.Request.Context(), "AWSHandlerHttp.GetAccount")
+	defer span.End()
+
+	id := c.Param("id")
+
+	if len(id) == 0 {
+		span.RecordError(errors.New("account id not setted"))
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": "account id not setted"})
+		return
+	}
+
+	result, err := obj.Service.GetAccount(ctx, id)
+	if errors.Is(err, entity.ErrAccountNotFound) {
+		c.JSON(http.StatusNotFound, "not found")
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	if result == nil {
+		c.JSON(http.StatusNotFound, "not found")
+		return
+	}
+
+	c.JSON(http.StatusAccepted, result)
+}
@@ -2,9 +2,15 @@ package handler
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/arm"
+	"github.com/Azure/azure-sdk-for-go/sdk/resourcemanager/resources/armresources"
 	"github.com/gin-gonic/gin"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
 	"github.com/synera-br/golang-cloud-collector/internal/core/service"
 	"github.com/synera-br/golang-cloud-collector/pkg/otelpkg"
 )
@@ -14,18 +20,20 @@ type AzureHandlerHttpInterface interface {
 	FindByResourceGroup(c *gin.Context)
 	FindByTag(c *gin.Context)
 	GetSubscription(c *gin.Context)
+	Query(c *gin.Context)
+	GetResourceByID(c *gin.Context)
 }
 
 type AzureHandlerHttp struct {
-	Service service.AzureServiceInterface
-	Tracer  *otelpkg.OtelPkgInstrument
+	Services []service.AzureServiceInterface
+	Tracer   *otelpkg.OtelPkgInstrument
 }
 
-func NewAzureHandlerHttp(svc service.AzureServiceInterface, otl *otelpkg.OtelPkgInstrument, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) AzureHandlerHttpInterface {
+func NewAzureHandlerHttp(svc []service.AzureServiceInterface, otl *otelpkg.OtelPkgInstrument, routerGroup *gin.RouterGroup, middleware ...func(c *gin.Context)) AzureHandlerHttpInterface {
 
 	azure := &AzureHandlerHttp{
-		Service: svc,
-		Tracer:  otl,
+		Services: svc,
+		Tracer:   otl,
 	}
 
 	azure.handlers(routerGroup, middleware...)
@@ -43,16 +51,43 @@ func (c *AzureHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware ...
 	routerGroup.GET("/azure/:name", c.FindByResourceGroup)
 	routerGroup.GET("/azure/tags", c.FindByTag)
 	routerGroup.GET("/azure/subscription/:name", c.GetSubscription)
+	routerGroup.POST("/azure/query", append(middlewareList, c.Query)...)
+	routerGroup.GET("/azure/resources/*id", append(middlewareList, c.GetResourceByID)...)
 
 }
 
+// selectService
+// Seleciona a conta Azure pelo query param account.
+// Sem o parâmetro, usa a única conta configurada ou a conta default
+func (obj *AzureHandlerHttp) selectService(c *gin.Context) (service.AzureServiceInterface, error) {
+
+	account := c.Query("account")
+	if account == "" {
+		if len(obj.Services) == 1 {
+			return obj.Services[0], nil
+		}
+		account = entity.DefaultAccount
+	}
+
+	for _, svc := range obj.Services {
+		if strings.EqualFold(svc.Account(), account) {
+			return svc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("azure account %s not found", account)
+}
+
 // AzureListResources    godoc
 // @Summary     list all resources from subscription
 // @Tags        azure
 // @Accept       json
 // @Produce     json
 // @Description get all azure register
+// @Param       account query string false "azure account name"
+// @Param       since query string false "only resources created or changed since this RFC3339 timestamp"
 // @Success     200 {object} []interface{}
+// @Failure     400 {object} string
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /azure [get]
@@ -60,7 +95,27 @@ func (obj *AzureHandlerHttp) ListResources(c *gin.Context) {
 	ctx, span := obj.Tracer.Tracer.Start(c.Request.Context(), "AzureHandlerHttp.ListResources")
 	defer span.End()
 
-	result, err := obj.Service.ListResources(ctx)
+	svc, err := obj.selectService(c)
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	var result []*armresources.GenericResourceExpanded
+	if since := c.Query("since"); since != "" {
+		sinceTime, parseErr := time.Parse(time.RFC3339, since)
+		if parseErr != nil {
+			span.RecordError(parseErr)
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": "since must be a RFC3339 timestamp"})
+			return
+		}
+		result, err = svc.ListResourcesSince(ctx, sinceTime)
+	} else {
+		result, err = svc.ListResources(ctx)
+	}
 	if err != nil {
 		span.RecordError(err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -84,6 +139,7 @@ func (obj *AzureHandlerHttp) ListResources(c *gin.Context) {
 // @Produce     json
 // @Param       name path string true "name"
 // @Description get all azure register
+// @Param       account query string false "azure account name"
 // @Success     200 {object} []interface{}
 // @Failure     404 {object} string
 // @Failure     500 {object} string
@@ -101,7 +157,15 @@ func (obj *AzureHandlerHttp) FindByResourceGroup(c *gin.Context) {
 		return
 	}
 
-	result, err := obj.Service.ListResourcesByResourceGroup(ctx, rsg)
+	svc, err := obj.selectService(c)
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	result, err := svc.ListResourcesByResourceGroup(ctx, rsg)
 	if err != nil {
 		span.RecordError(err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -126,6 +190,7 @@ func (obj *AzureHandlerHttp) FindByResourceGroup(c *gin.Context) {
 // @Description find resources by tags
 // @Param key        query string false "Key filter"
 // @Param value        query string false "value filter"
+// @Param       account query string false "azure account name"
 // @Success     200 {object} []interface{}
 // @Failure     404 {object} string
 // @Failure     500 {object} string
@@ -134,14 +199,22 @@ func (obj *AzureHandlerHttp) FindByTag(c *gin.Context) {
 	ctx, span := obj.Tracer.Tracer.Start(c.Request.Context(), "AzureHandlerHttp.ListResources")
 	defer span.End()
 
-	if len(c.Request.URL.Query()) != 2 {
+	if c.Query("key") == "" || c.Query("value") == "" {
 		span.RecordError(errors.New("key and value of tags not setted"))
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": "key and value of tags not setted"})
 		return
 	}
 
-	result, err := obj.Service.ListResourcesByTag(ctx, c.Request.URL.Query().Get("key"), c.Request.URL.Query().Get("value"))
+	svc, err := obj.selectService(c)
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	result, err := svc.ListResourcesByTag(ctx, c.Request.URL.Query().Get("key"), c.Request.URL.Query().Get("value"))
 	if err != nil {
 		span.RecordError(err)
 		c.JSON(http.StatusInternalServerError, gin.H{
@@ -164,6 +237,7 @@ func (obj *AzureHandlerHttp) FindByTag(c *gin.Context) {
 // @Produce     json
 // @Description get subscription information
 // @Param       name path string true "name"
+// @Param       account query string false "azure account name"
 // @Success     200 {object} []interface{}
 // @Failure     404 {object} string
 // @Failure     500 {object} string
@@ -181,7 +255,15 @@ func (obj *AzureHandlerHttp) GetSubscription(c *gin.Context) {
 		return
 	}
 
-	result, err := obj.Service.GetSubscription(ctx, subs, subs)
+	svc, err := obj.selectService(c)
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	result, err := svc.GetSubscription(ctx, subs, subs)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, gin.H{
 			"error": err.Error()})
@@ -195,3 +277,115 @@ func (obj *AzureHandlerHttp) GetSubscription(c *gin.Context) {
 
 	c.JSON(http.StatusAccepted, result)
 }
+
+// AzureQuery    godoc
+// @Summary     run a KQL query on Azure Resource Graph
+// @Tags        azure
+// @Accept       json
+// @Produce     json
+// @Description run a custom KQL query across the account subscriptions
+// @Param       account query string false "azure account name"
+// @Param       request body entity.AzureQuery true "KQL query"
+// @Success     200 {object} []interface{}
+// @Failure     400 {object} string
+// @Failure     404 {object} string
+// @Failure     500 {object} string
+// @Router      /azure/query [post]
+func (obj *AzureHandlerHttp) Query(c *gin.Context) {
+	ctx, span := obj.Tracer.Tracer.Start(c.Request.Context(), "AzureHandlerHttp.Query")
+	defer span.End()
+
+	var request entity.AzureQuery
+	if err := c.ShouldBindJSON(&request); err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	svc, err := obj.selectService(c)
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	result, err := svc.Query(ctx, request.Query)
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	if len(result) == 0 {
+		c.JSON(http.StatusNotFound, "not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// AzureGetResourceByID    godoc
+// @Summary     get one resource with its full properties
+// @Tags        azure
+// @Accept       json
+// @Produce     json
+// @Description get the resource by its ARM ID, for example /subscriptions/{id}/resourceGroups/{rg}/providers/Microsoft.Web/sites/{name}
+// @Param       id path string true "ARM resource ID"
+// @Param       account query string false "azure account name"
+// @Success     200 {object} entity.AzureResourceDetail
+// @Failure     400 {object} string
+// @Failure     404 {object} string
+// @Failure     500 {object} string
+// @Router      /azure/resources/{id} [get]
+func (obj *AzureHandlerHttp) GetResourceByID(c *gin.Context) {
+	ctx, span := obj.Tracer.Tracer.Start(c.Request.Context(), "AzureHandlerHttp.GetResourceByID")
+	defer span.End()
+
+	id := c.Param("id")
+
+	if len(strings.Trim(id, "/")) == 0 {
+		span.RecordError(errors.New("resource id not setted"))
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": "resource id not setted"})
+		return
+	}
+
+	if _, err := arm.ParseResourceID(id); err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	svc, err := obj.selectService(c)
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	result, err := svc.GetResourceByID(ctx, id)
+	if errors.Is(err, entity.ErrSubscriptionNotCollected) {
+		span.RecordError(err)
+		c.JSON(http.StatusNotFound, gin.H{
+			"error": err.Error()})
+		return
+	}
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	if result == nil {
+		c.JSON(http.StatusNotFound, "not found")
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
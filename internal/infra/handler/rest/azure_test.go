@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+	"github.com/synera-br/golang-cloud-collector/internal/core/service"
+	"github.com/synera-br/golang-cloud-collector/pkg/otelpkg"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// testTracer
+// Tracer sem exportador para os testes
+func testTracer() *otelpkg.OtelPkgInstrument {
+	return &otelpkg.OtelPkgInstrument{Tracer: noop.NewTracerProvider().Tracer("test")}
+}
+
+// fakeAzureService
+// Conta Azure que coleta apenas a assinatura sub-a, onde existe somente o recurso site-a
+type fakeAzureService struct {
+	service.AzureServiceInterface
+}
+
+func (s *fakeAzureService) Account() string {
+	return "default"
+}
+
+func (s *fakeAzureService) GetResourceByID(_ context.Context, id string) (*entity.AzureResourceDetail, error) {
+	if !strings.HasPrefix(id, "/subscriptions/sub-a/") {
+		return nil, fmt.Errorf("subscription: %w", entity.ErrSubscriptionNotCollected)
+	}
+	if strings.HasSuffix(id, "/site-a") {
+		return &entity.AzureResourceDetail{ID: id, Name: "site-a"}, nil
+	}
+	return nil, nil
+}
+
+func TestAzureHandlerGetResourceByID(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewAzureHandlerHttp([]service.AzureServiceInterface{&fakeAzureService{}}, testTracer(), router.Group("/api"))
+
+	tests := []struct {
+		name string
+		path string
+		want int
+	}{
+		{name: "found", path: "/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Web/sites/site-a", want: http.StatusOK},
+		{name: "not found", path: "/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Web/sites/site-b", want: http.StatusNotFound},
+		{name: "subscription not collected", path: "/subscriptions/sub-b/resourceGroups/rg/providers/Microsoft.Web/sites/site-a", want: http.StatusNotFound},
+		{name: "malformed id", path: "/not-an-arm-id", want: http.StatusBadRequest},
+		{name: "empty id", path: "/", want: http.StatusBadRequest},
+		{name: "unknown account", path: "/subscriptions/sub-a/resourceGroups/rg/providers/Microsoft.Web/sites/site-a?account=other", want: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/azure/resources"+tt.path, nil))
+			if w.Code != tt.want {
+				t.Errorf("GET %s = %d, want %d: %s", tt.path, w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -47,11 +48,13 @@ func (c *BackstageHandlerHttp) handlers(routerGroup *gin.RouterGroup, middleware
 // @Accept       json
 // @Produce     json
 // @Description get all backstage register
-// @Param provider        query string true "name of provider"
+// @Param provider        query string false "name of provider, empty syncs every configured provider"
 // @Param account        query string false "name of account to filter"
 // @Param key        query string false "tag key to filter"
 // @Param value        query string false "tag value to filter"
+// @Param profile        query string false "rendering profile of the response"
 // @Success     200 {object} entity.Trigger
+// @Failure     400 {object} string
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /backstage [post]
@@ -68,6 +71,16 @@ func (obj *BackstageHandlerHttp) TriggerSyncProvider(c *gin.Context) {
 		return
 	}
 
+	// o perfil é validado antes da sincronização, que publica as entidades
+	profile := c.Query("profile")
+	if profile != "" {
+		if _, err := obj.Service.RenderProfile(ctx, profile, nil); err != nil {
+			span.RecordError(err)
+			c.JSON(profileStatus(err), gin.H{"error": err.Error()})
+			return
+		}
+	}
+
 	response, err := obj.Service.TriggerSyncProvider(ctx, trigger)
 	if err != nil {
 		span.RecordError(err)
@@ -76,6 +89,18 @@ func (obj *BackstageHandlerHttp) TriggerSyncProvider(c *gin.Context) {
 		return
 	}
 
+	if profile != "" {
+		documents, err := obj.Service.RenderProfile(ctx, profile, response)
+		if err != nil {
+			span.RecordError(err)
+			c.JSON(http.StatusInternalServerError, gin.H{
+				"error": err.Error()})
+			return
+		}
+		c.JSON(http.StatusAccepted, documents)
+		return
+	}
+
 	c.JSON(http.StatusAccepted, response)
 }
 
@@ -87,8 +112,10 @@ func (obj *BackstageHandlerHttp) TriggerSyncProvider(c *gin.Context) {
 // @Param name        query string false "filter resource by name"
 // @Param kind        query string false "filter resource by kind"
 // @Param namespace        query string false "filter resource by namespace"
+// @Param profile        query string false "rendering profile of the entities published by the last syncs, does not trigger a sync"
 // @Description get all backstage register
-// @Success     200 {object} []entity.KindReource
+// @Success     200 {object} []entity.BackstageEntity
+// @Failure     400 {object} string
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /backstage [get]
@@ -96,11 +123,29 @@ func (obj *BackstageHandlerHttp) GetAllKinds(c *gin.Context) {
 	ctx, span := obj.Tracer.Tracer.Start(c.Request.Context(), "BackstageHandlerHttp.GetAllKinds")
 	defer span.End()
 
-	result, err := obj.Service.GetAllKinds(ctx, entity.FilterKind{
+	filter := entity.FilterKind{
 		Name:      c.Request.URL.Query().Get("name"),
 		Kind:      c.Request.URL.Query().Get("kind"),
 		Namespace: c.Request.URL.Query().Get("namespace"),
-	})
+	}
+
+	if profile := c.Query("profile"); profile != "" {
+		documents, err := obj.Service.GetAllDocuments(ctx, filter, profile)
+		if err != nil {
+			span.RecordError(err)
+			c.JSON(profileStatus(err), gin.H{
+				"error": err.Error()})
+			return
+		}
+		if len(documents) == 0 {
+			c.JSON(http.StatusNotFound, "not found")
+			return
+		}
+		c.JSON(http.StatusAccepted, documents)
+		return
+	}
+
+	result, err := obj.Service.GetAllKinds(ctx, filter)
 
 	if err != nil {
 		span.RecordError(err)
@@ -126,7 +171,7 @@ func (obj *BackstageHandlerHttp) GetAllKinds(c *gin.Context) {
 // @Param       kind path string true "kind of the resource"
 // @Param       name path string true "name of the resource"
 // @Description get all backstage register
-// @Success     200 {object} entity.KindReource
+// @Success     200 {object} entity.BackstageEntity
 // @Failure     404 {object} string
 // @Failure     500 {object} string
 // @Router      /backstage/{namespace}/{kind}/{name} [get]
@@ -162,3 +207,12 @@ func (obj *BackstageHandlerHttp) GetKind(c *gin.Context) {
 
 	c.JSON(http.StatusAccepted, result)
 }
+
+// profileStatus
+// Perfil não configurado é um erro da requisição
+func profileStatus(err error) int {
+	if errors.Is(err, entity.ErrProfileNotFound) {
+		return http.StatusBadRequest
+	}
+	return http.StatusInternalServerError
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/gin-gonic/gin"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+	"github.com/synera-br/golang-cloud-collector/internal/core/service"
+	"github.com/synera-br/golang-cloud-collector/pkg/otelpkg"
+)
+
+type EventGridHandlerHttpInterface interface {
+	Handshake(c *gin.Context)
+	ReceiveEvents(c *gin.Context)
+}
+
+// EventGridHandlerHttp
+// Webhook do Event Grid. O Event Grid não envia o token da API, então as rotas usam o middleware validateSecret
+// no lugar do ValidateToken: o query param secret precisa conferir com o event_grid.secret de alguma conta.
+// Cada evento ainda é aceito apenas pela conta dona da assinatura com o mesmo segredo
+type EventGridHandlerHttp struct {
+	Services  []service.AzureServiceInterface
+	Backstage service.BackstageServiceInterface
+	Tracer    *otelpkg.OtelPkgInstrument
+}
+
+func NewEventGridHandlerHttp(svc []service.AzureServiceInterface, backstage service.BackstageServiceInterface, otl *otelpkg.OtelPkgInstrument, routerGroup *gin.RouterGroup) EventGridHandlerHttpInterface {
+
+	eventGrid := &EventGridHandlerHttp{
+		Services:  svc,
+		Backstage: backstage,
+		Tracer:    otl,
+	}
+
+	eventGrid.handlers(routerGroup)
+
+	return eventGrid
+}
+
+func (c *EventGridHandlerHttp) handlers(routerGroup *gin.RouterGroup) {
+	routerGroup.OPTIONS("/azure/events", c.validateSecret, c.Handshake)
+	routerGroup.POST("/azure/events", c.validateSecret, c.ReceiveEvents)
+}
+
+// validateSecret
+// Autenticação das rotas do webhook pelo query param secret
+func (obj *EventGridHandlerHttp) validateSecret(c *gin.Context) {
+
+	secret := c.Query("secret")
+	for _, svc := range obj.Services {
+		if svc.ValidateEventSecret(secret) {
+			c.Next()
+			return
+		}
+	}
+
+	c.JSON(http.StatusUnauthorized, gin.H{"error": "not authorized"})
+	c.Abort()
+}
+
+// retryableEventError
+// Falhas temporárias do ARM (429 e 5xx), de rede ou da publicação. As demais respostas do ARM
+// se repetiriam a cada nova entrega do Event Grid
+func retryableEventError(err error) bool {
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode == http.StatusTooManyRequests || respErr.StatusCode >= http.StatusInternalServerError
+	}
+	return true
+}
+
+// selectService
+// Conta que coleta a assinatura do evento, desde que o segredo confira
+func (obj *EventGridHandlerHttp) selectService(c *gin.Context, event *entity.EventGridEvent) (service.AzureServiceInterface, error) {
+
+	subscription := event.SubscriptionID()
+	if subscription == "" {
+		return nil, fmt.Errorf("event %s has no subscription in topic %s", event.ID, event.GetTopic())
+	}
+
+	for _, svc := range obj.Services {
+		if svc.ManagesSubscription(c.Request.Context(), subscription) && svc.ValidateEventSecret(c.Query("secret")) {
+			return svc, nil
+		}
+	}
+
+	return nil, fmt.Errorf("subscription %s with this secret: %w", subscription, entity.ErrSubscriptionNotCollected)
+}
+
+// AzureEventGridHandshake    godoc
+// @Summary     CloudEvents webhook validation
+// @Tags        azure
+// @Description abuse protection handshake of the CloudEvents 1.0 schema
+// @Param       WebHook-Request-Origin header string true "origin of the event subscription"
+// @Success     200
+// @Router      /azure/events [options]
+func (obj *EventGridHandlerHttp) Handshake(c *gin.Context) {
+	_, span := obj.Tracer.Tracer.Start(c.Request.Context(), "EventGridHandlerHttp.Handshake")
+	defer span.End()
+
+	c.Header("WebHook-Allowed-Origin", c.GetHeader("WebHook-Request-Origin"))
+	c.Header("WebHook-Allowed-Rate", "*")
+	c.Status(http.StatusOK)
+}
+
+// AzureEventGridReceiveEvents    godoc
+// @Summary     receive Azure Event Grid resource events
+// @Tags        azure
+// @Accept       json
+// @Produce     json
+// @Description completes the subscription validation and refreshes or tombstones the entity of each ResourceWriteSuccess/ResourceDeleteSuccess event.
+// @Description events of subscriptions not collected with the secret are skipped, and their subscription validation is rejected with 404.
+// @Description The batch fails only when an event fails with a retryable error
+// @Param       secret query string true "event_grid.secret of the account"
+// @Param       request body []entity.EventGridEvent true "Event Grid events"
+// @Success     200 {object} entity.EventGridValidationResponse
+// @Failure     400 {object} string
+// @Failure     401 {object} string
+// @Failure     404 {object} string
+// @Failure     500 {object} string
+// @Router      /azure/events [post]
+func (obj *EventGridHandlerHttp) ReceiveEvents(c *gin.Context) {
+	ctx, span := obj.Tracer.Tracer.Start(c.Request.Context(), "EventGridHandlerHttp.ReceiveEvents")
+	defer span.End()
+
+	body, err := io.ReadAll(c.Request.Body)
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	events, err := entity.ParseEventGridEvents(body)
+	if err != nil {
+		span.RecordError(err)
+		c.JSON(http.StatusBadRequest, gin.H{
+			"error": err.Error()})
+		return
+	}
+
+	var retry []error
+	var published []entity.BackstageEntity
+	for i := range events {
+		event := &events[i]
+
+		svc, err := obj.selectService(c, event)
+		if err != nil {
+			span.RecordError(err)
+			// a validação de uma assinatura que nenhuma conta coleta não pode ser confirmada
+			if event.GetType() == entity.EventGridSubscriptionValidation {
+				status := http.StatusBadRequest
+				if errors.Is(err, entity.ErrSubscriptionNotCollected) {
+					status = http.StatusNotFound
+				}
+				c.JSON(status, gin.H{
+					"error": err.Error()})
+				return
+			}
+			continue
+		}
+
+		switch event.GetType() {
+		case entity.EventGridSubscriptionValidation:
+			var data entity.EventGridValidationData
+			if err := json.Unmarshal(event.Data, &data); err != nil || data.ValidationCode == "" {
+				span.RecordError(errors.New("validation code not setted"))
+				c.JSON(http.StatusBadRequest, gin.H{
+					"error": "validation code not setted"})
+				return
+			}
+			c.JSON(http.StatusOK, entity.EventGridValidationResponse{ValidationResponse: data.ValidationCode})
+			return
+
+		case entity.EventGridResourceWriteSuccess, entity.EventGridResourceDeleteSuccess:
+			var data entity.EventGridResourceData
+			if err := json.Unmarshal(event.Data, &data); err != nil || data.ResourceURI == "" {
+				span.RecordError(fmt.Errorf("event %s has no resourceUri", event.ID))
+				continue
+			}
+
+			resources, err := svc.ResourceChanged(ctx, data.ResourceURI, event.GetType() == entity.EventGridResourceDeleteSuccess)
+			if err != nil {
+				err = fmt.Errorf("event %s: %w", event.ID, err)
+				span.RecordError(err)
+				if retryableEventError(err) {
+					retry = append(retry, err)
+				}
+				continue
+			}
+			if len(resources) == 0 {
+				continue
+			}
+
+			kinds, err := obj.Backstage.PublishCloudResources(ctx, svc, resources)
+			if err != nil {
+				err = fmt.Errorf("event %s: %w", event.ID, err)
+				span.RecordError(err)
+				retry = append(retry, err)
+				continue
+			}
+			published = append(published, kinds...)
+		}
+	}
+
+	// o Event Grid entrega o lote novamente; os eventos já processados são republicados com o mesmo conteúdo
+	if len(retry) > 0 {
+		c.JSON(http.StatusInternalServerError, gin.H{
+			"error": errors.Join(retry...).Error()})
+		return
+	}
+
+	c.JSON(http.StatusOK, published)
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/gin-gonic/gin"
+	"github.com/synera-br/golang-cloud-collector/internal/core/entity"
+	"github.com/synera-br/golang-cloud-collector/internal/core/service"
+)
+
+// eventGridAzureService
+// Conta com o segredo s3cret que coleta apenas a assinatura sub-a.
+// Recursos com nome throttled ou forbidden simulam as falhas do ARM
+type eventGridAzureService struct {
+	service.AzureServiceInterface
+}
+
+func (s *eventGridAzureService) ValidateEventSecret(secret string) bool {
+	return secret == "s3cret"
+}
+
+func (s *eventGridAzureService) ManagesSubscription(_ context.Context, subscriptionID string) bool {
+	return subscriptionID == "sub-a"
+}
+
+func (s *eventGridAzureService) ResourceChanged(_ context.Context, resourceID string, _ bool) ([]entity.CloudResource, error) {
+	switch {
+	case strings.HasSuffix(resourceID, "/throttled"):
+		return nil, &azcore.ResponseError{StatusCode: http.StatusTooManyRequests}
+	case strings.HasSuffix(resourceID, "/forbidden"):
+		return nil, &azcore.ResponseError{StatusCode: http.StatusForbidden}
+	}
+	return []entity.CloudResource{{ID: resourceID}}, nil
+}
+
+// eventGridBackstage
+// Publica uma entidade por recurso ou falha quando err está preenchido
+type eventGridBackstage struct {
+	service.BackstageServiceInterface
+	err error
+}
+
+func (b *eventGridBackstage) PublishCloudResources(_ context.Context, _ entity.CloudProviderInterface, resources []entity.CloudResource) ([]entity.BackstageEntity, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+	result := make([]entity.BackstageEntity, 0, len(resources))
+	for _, resource := range resources {
+		result = append(result, entity.BackstageEntity{Kind: "Resource", Metadata: entity.BackstageMetadata{Name: resource.ID}})
+	}
+	return result, nil
+}
+
+func TestEventGridHandlerReceiveEvents(t *testing.T) {
+
+	validation := func(subscription string) string {
+		return `[{"id":"1","topic":"/subscriptions/` + subscription + `","eventType":"Microsoft.EventGrid.SubscriptionValidationEvent","data":{"validationCode":"code-1"}}]`
+	}
+	write := func(subscription, name string) string {
+		return `{"id":"` + name + `","topic":"/subscriptions/` + subscription + `","eventType":"Microsoft.Resources.ResourceWriteSuccess",` +
+			`"data":{"resourceUri":"/subscriptions/` + subscription + `/resourceGroups/rg/providers/Microsoft.Web/sites/` + name + `"}}`
+	}
+
+	tests := []struct {
+		name       string
+		secret     string
+		body       string
+		publishErr error
+		want       int
+		contains   string
+	}{
+		{name: "wrong secret", secret: "other", body: validation("sub-a"), want: http.StatusUnauthorized},
+		{name: "subscription validation", secret: "s3cret", body: validation("sub-a"), want: http.StatusOK, contains: `"validationResponse":"code-1"`},
+		{name: "validation of a subscription not collected", secret: "s3cret", body: validation("sub-b"), want: http.StatusNotFound, contains: "subscription not collected"},
+		{name: "validation without subscription", secret: "s3cret", body: `[{"id":"1","topic":"/providers/x","eventType":"Microsoft.EventGrid.SubscriptionValidationEvent","data":{"validationCode":"code-1"}}]`, want: http.StatusBadRequest},
+		{name: "validation without code", secret: "s3cret", body: `[{"id":"1","topic":"/subscriptions/sub-a","eventType":"Microsoft.EventGrid.SubscriptionValidationEvent","data":{}}]`, want: http.StatusBadRequest},
+		{name: "invalid body", secret: "s3cret", body: "{", want: http.StatusBadRequest},
+		{name: "resource write", secret: "s3cret", body: "[" + write("sub-a", "site-a") + "]", want: http.StatusOK, contains: "/sites/site-a"},
+		{name: "event of a subscription not collected is skipped", secret: "s3cret", body: "[" + write("sub-b", "site-b") + "," + write("sub-a", "site-a") + "]", want: http.StatusOK, contains: "/sites/site-a"},
+		{name: "non retryable arm error is skipped", secret: "s3cret", body: "[" + write("sub-a", "forbidden") + "]", want: http.StatusOK},
+		{name: "throttled arm error fails the batch", secret: "s3cret", body: "[" + write("sub-a", "throttled") + "," + write("sub-a", "site-a") + "]", want: http.StatusInternalServerError},
+		{name: "publish error fails the batch", secret: "s3cret", body: "[" + write("sub-a", "site-a") + "]", publishErr: errors.New("broker unavailable"), want: http.StatusInternalServerError, contains: "broker unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			router := gin.New()
+			NewEventGridHandlerHttp([]service.AzureServiceInterface{&eventGridAzureService{}}, &eventGridBackstage{err: tt.publishErr}, testTracer(), router.Group("/api"))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/azure/events?secret="+tt.secret, strings.NewReader(tt.body)))
+			if w.Code != tt.want {
+				t.Fatalf("POST /azure/events = %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.contains) {
+				t.Errorf("POST /azure/events body = %s, want it to contain %s", w.Body.String(), tt.contains)
+			}
+		})
+	}
+}
+
+func TestEventGridHandlerHandshake(t *testing.T) {
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	NewEventGridHandlerHttp([]service.AzureServiceInterface{&eventGridAzureService{}}, &eventGridBackstage{}, testTracer(), router.Group("/api"))
+
+	req := httptest.NewRequest(http.MethodOptions, "/api/azure/events?secret=s3cret", nil)
+	req.Header.Set("WebHook-Request-Origin", "eventgrid.azure.net")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK || w.Header().Get("WebHook-Allowed-Origin") != "eventgrid.azure.net" {
+		t.Errorf("OPTIONS /azure/events = %d with origin %q", w.Code, w.Header().Get("WebHook-Allowed-Origin"))
+	}
+}
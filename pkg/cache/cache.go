@@ -8,6 +8,8 @@ import (
 
 type CacheInterface interface {
 	Set(context.Context, string, []byte, time.Duration) error
+	SetNX(context.Context, string, []byte, time.Duration) (bool, error)
+	Expire(context.Context, string, time.Duration) (bool, error)
 	Get(context.Context, string) ([]byte, error)
 	Exists(context.Context, string) (int64, error)
 	Del(context.Context, string) (int64, error)
@@ -25,6 +27,18 @@ func (c *CacheConfig) Set(ctx context.Context, key string, val []byte, ttl time.
 	return nil
 }
 
+// SetNX
+// Grava a chave somente quando ela não existe. Retorna false quando outra gravação chegou antes
+func (c *CacheConfig) SetNX(ctx context.Context, key string, val []byte, ttl time.Duration) (bool, error) {
+	return c.Client.SetNX(ctx, fmt.Sprintf("%s_%s", c.Prefix, key), string(val), ttl).Result()
+}
+
+// Expire
+// Renova o TTL da chave. Retorna false quando a chave não existe mais
+func (c *CacheConfig) Expire(ctx context.Context, key string, ttl time.Duration) (bool, error) {
+	return c.Client.Expire(ctx, fmt.Sprintf("%s_%s", c.Prefix, key), ttl).Result()
+}
+
 func (c *CacheConfig) Get(ctx context.Context, key string) ([]byte, error) {
 	result, err := c.Client.Get(ctx, fmt.Sprintf("%s_%s", c.Prefix, key)).Result()
 	if err != nil {